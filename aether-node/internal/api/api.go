@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"node/internal/config"
 	"node/internal/persistence"
+	"node/internal/rendering"
 	"node/internal/state"
 	"strconv"
 	"strings"
@@ -50,6 +51,17 @@ func registerApiRoutes(state *RouteCtx) {
 	route("/scenes", http.MethodGet, "*", state.getScenesHandler)
 	route("/upload", http.MethodPost, "multipart/form-data", state.postUploadHandler)
 	route("/render", http.MethodPost, "application/json", state.postRenderHandler)
+	route("/queue", http.MethodGet, "*", state.getQueueHandler)
+	route("/jobs", http.MethodGet, "*", state.getJobsHandler)
+	route("/jobs/{id}", http.MethodGet, "*", state.getJobHandler)
+	route("/jobs/{id}/result", http.MethodGet, "*", state.getJobResultHandler)
+	route("/jobs/{id}/log", http.MethodGet, "*", state.getJobLogHandler)
+	route("/jobs/{id}/crash-report", http.MethodGet, "*", state.getJobCrashReportHandler)
+	route("/jobs/{id}/frames", http.MethodGet, "*", state.getJobFramesHandler)
+	route("/jobs/{id}/frames/{frame}", http.MethodGet, "*", state.getJobFrameHandler)
+	route("/jobs/{id}/cancel", http.MethodPost, "*", state.postCancelJobHandler)
+	route("/jobs/{id}/pause", http.MethodPost, "*", state.postPauseJobHandler)
+	route("/jobs/{id}/resume", http.MethodPost, "*", state.postResumeJobHandler)
 	route("/status", http.MethodGet, "*", state.getStatusHandler)
 	route("/renders", http.MethodGet, "*", state.getRenderResult)
 }
@@ -59,13 +71,28 @@ func RespondJson(w http.ResponseWriter, value map[string]interface{}) {
 	_ = json.NewEncoder(w).Encode(value)
 }
 
+func RespondJsonStatus(w http.ResponseWriter, status int, value map[string]interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
 func InitializeApi(port uint16, node *state.AetherNode, cfg config.NodeConfig) {
 	s := &RouteCtx{
 		Node:       node,
 		Config:     &cfg,
 		SceneStore: persistence.LoadStoredScenes(&cfg),
+		Queue:      persistence.LoadJobQueue(&cfg),
+		Jobs:       persistence.LoadJobIndex(&cfg),
 	}
 
+	rendering.DropInterruptedFromQueue(s.Config, s.Queue, s.Jobs)
+	if cfg.Node.ResumeInterrupted {
+		rendering.ResumeInterruptedJobs(s.Config, s.Queue, s.Jobs)
+	}
+
+	go rendering.RunWorker(s.Config, &node.State, node.Blenders, s.SceneStore, s.Queue, s.Jobs)
+
 	logrus.Infof("Aether node is listening on http://localhost:%d\n", port)
 
 	registerApiRoutes(s)
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"node/internal/dto/jobs"
+	"node/internal/state"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Where the frames of a job can be read from. While Blender is running they are single files in the output
+// directory, once the results have been collected they are entries of the result file.
+type frameSource struct {
+	frames    []state.SavedFrame
+	outputDir string
+	zipPath   string
+}
+
+func (ctx *RouteCtx) frameSourceOf(job *state.Job) frameSource {
+	if job.IsFinished() {
+		return frameSource{frames: job.Frames, zipPath: job.OutputPath}
+	}
+
+	renderState := ctx.Node.State.Renderer()
+	if renderState == nil || renderState.JobID != job.ID {
+		return frameSource{}
+	}
+
+	if renderState.OutputPath != "" {
+		return frameSource{frames: renderState.SavedFrames, zipPath: renderState.OutputPath}
+	}
+	return frameSource{frames: renderState.SavedFrames, outputDir: renderState.OutputDir}
+}
+
+// List the frames of a job that can already be downloaded, also while it is still rendering
+func (ctx *RouteCtx) getJobFramesHandler(writer http.ResponseWriter, req *http.Request) {
+	job := ctx.jobFromPath(writer, req)
+	if job == nil {
+		return
+	}
+
+	frames := ctx.frameSourceOf(job).frames
+	if frames == nil {
+		frames = []state.SavedFrame{}
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(writer).Encode(jobs.FramesResponse{JobID: job.ID, Status: job.Status, Frames: frames})
+}
+
+// Download a single rendered frame of a job
+func (ctx *RouteCtx) getJobFrameHandler(writer http.ResponseWriter, req *http.Request) {
+	job := ctx.jobFromPath(writer, req)
+	if job == nil {
+		return
+	}
+
+	frameNumber, err := strconv.Atoi(req.PathValue("frame"))
+	if err != nil {
+		http.Error(writer, "Invalid frame number", http.StatusBadRequest)
+		logrus.Debugf("Could not parse frame number: %s\n", err)
+		return
+	}
+
+	source := ctx.frameSourceOf(job)
+
+	var frame *state.SavedFrame = nil
+	for i := range source.frames {
+		if source.frames[i].Frame == frameNumber {
+			frame = &source.frames[i]
+			break
+		}
+	}
+
+	if frame == nil {
+		http.Error(writer, "This frame is not available", http.StatusNotFound)
+		logrus.Debugf("Frame %d of job %s is not available\n", frameNumber, job.ID)
+		return
+	}
+
+	if source.outputDir != "" {
+		serveFrameFile(writer, req, filepath.Join(source.outputDir, filepath.FromSlash(frame.File)))
+		return
+	}
+
+	serveZippedFrame(writer, source.zipPath, frame.File)
+}
+
+func setFrameHeaders(writer http.ResponseWriter, filename string) {
+	contentType := mime.TypeByExtension(path.Ext(filename))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	writer.Header().Set("Content-Type", contentType)
+	writer.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", path.Base(filename)))
+}
+
+func serveFrameFile(writer http.ResponseWriter, req *http.Request, path string) {
+	f, err := os.Open(path)
+	if err != nil {
+		http.Error(writer, "The frame no longer exists", http.StatusNotFound)
+		logrus.Debugf("Could not open frame (%s): %s\n", path, err)
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		http.Error(writer, "Could not stat file", http.StatusInternalServerError)
+		logrus.Debugf("Could not stat file (%s): %s\n", path, err)
+		return
+	}
+
+	setFrameHeaders(writer, filepath.Base(path))
+	http.ServeContent(writer, req, filepath.Base(path), info.ModTime(), f)
+}
+
+func serveZippedFrame(writer http.ResponseWriter, zipPath string, name string) {
+	archive, err := zip.OpenReader(zipPath)
+	if err != nil {
+		http.Error(writer, "The render result no longer exists", http.StatusNotFound)
+		logrus.Debugf("Could not open render result (%s): %s\n", zipPath, err)
+		return
+	}
+	defer archive.Close()
+
+	for _, entry := range archive.File {
+		if filepath.ToSlash(entry.Name) != name {
+			continue
+		}
+
+		content, err := entry.Open()
+		if err != nil {
+			http.Error(writer, "Could not read frame from the render result", http.StatusInternalServerError)
+			logrus.Debugf("Could not read %s from %s: %s\n", name, zipPath, err)
+			return
+		}
+		defer content.Close()
+
+		setFrameHeaders(writer, name)
+		writer.Header().Set("Content-Length", strconv.FormatUint(entry.UncompressedSize64, 10))
+		_, _ = io.Copy(writer, content)
+		return
+	}
+
+	http.Error(writer, "The frame is missing from the render result", http.StatusNotFound)
+	logrus.Debugf("Render result %s does not contain %s\n", zipPath, name)
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Interval in which a followed log is checked for new output
+const logFollowInterval = 250 * time.Millisecond
+
+// Stream a log file to the client and keep sending new output until isFinished reports true
+// or the client goes away. The file does not need to exist yet.
+func followLog(writer http.ResponseWriter, req *http.Request, path string, isFinished func() bool) {
+	writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	writer.Header().Set("X-Content-Type-Options", "nosniff")
+	flusher, _ := writer.(http.Flusher)
+
+	var file *os.File = nil
+	defer func() {
+		if file != nil {
+			_ = file.Close()
+		}
+	}()
+
+	for {
+		// Evaluate before copying, so output written right before the job finished is not lost
+		finished := isFinished()
+
+		if file == nil {
+			f, err := os.Open(path)
+			if err != nil && !os.IsNotExist(err) {
+				logrus.Debugf("Could not open log (%s): %s\n", path, err)
+				return
+			}
+			file = f
+		}
+
+		if file != nil {
+			if _, err := io.Copy(writer, file); err != nil {
+				logrus.Debugf("Could not stream log (%s): %s\n", path, err)
+				return
+			}
+		}
+
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		if finished {
+			return
+		}
+
+		select {
+		case <-req.Context().Done():
+			return
+		case <-time.After(logFollowInterval):
+		}
+	}
+}
@@ -6,8 +6,10 @@ import (
 	"html/template"
 	"net/http"
 	"node/internal/banner"
+	"node/internal/blender"
 	"node/internal/config"
 	"node/internal/dto/id"
+	"node/internal/dto/jobs"
 	"node/internal/dto/progress"
 	"node/internal/dto/render"
 	"node/internal/dto/scenes"
@@ -19,16 +21,20 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/dustin/go-humanize"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
 type RouteCtx struct {
 	Node       *state.AetherNode
 	Config     *config.NodeConfig
-	SceneStore persistence.SceneIndex
+	SceneStore *persistence.SceneIndex
+	Queue      *persistence.JobQueue
+	Jobs       *persistence.JobIndex
 }
 
 // Print basic information page if showing the page fails for whatever reason
@@ -41,9 +47,16 @@ func (ctx *RouteCtx) handleFallbackInfoPage(writer http.ResponseWriter, request
 func (ctx *RouteCtx) getRootHandler(writer http.ResponseWriter, req *http.Request) {
 	tmpl, err := template.ParseFiles("static/index.html")
 
-	var status string = "IDLE"
-	if ctx.Node.State.RendererState != nil {
-		status = "RENDERING"
+	snapshot := ctx.Node.State.Snapshot()
+
+	var status = string(snapshot.Phase)
+	if snapshot.RendererState != nil && snapshot.RendererState.Paused {
+		status = "PAUSED"
+	}
+
+	var statusSince = ""
+	if snapshot.PhaseSince != 0 {
+		statusSince = time.Unix(0, snapshot.PhaseSince).Format("2006-01-02 15:04:05")
 	}
 
 	if err != nil {
@@ -51,13 +64,16 @@ func (ctx *RouteCtx) getRootHandler(writer http.ResponseWriter, req *http.Reques
 	}
 
 	err = tmpl.Execute(writer, map[string]interface{}{
-		"UUID":      ctx.Node.ID.String(),
-		"Name":      ctx.Node.Name,
-		"Port":      strconv.Itoa(int(ctx.Node.Port)),
-		"NodeColor": template.CSS(fmt.Sprintf("rgb(%d,%d,%d)", ctx.Node.Color.R, ctx.Node.Color.G, ctx.Node.Color.B)),
-		"Version":   version.AetherVersion,
-		"Blender":   ctx.Config.Node.Blender,
-		"Status":    status,
+		"UUID":           ctx.Node.ID.String(),
+		"Name":           ctx.Node.Name,
+		"Port":           strconv.Itoa(int(ctx.Node.Port)),
+		"NodeColor":      template.CSS(fmt.Sprintf("rgb(%d,%d,%d)", ctx.Node.Color.R, ctx.Node.Color.G, ctx.Node.Color.B)),
+		"Version":        version.AetherVersion,
+		"Blender":        ctx.Config.Node.Blender,
+		"BlenderVersion": blenderVersion(ctx.Node.Blenders.Default()),
+		"Degraded":       ctx.Node.Blenders.Degraded(),
+		"Status":         status,
+		"StatusSince":    statusSince,
 	})
 
 	if err != nil {
@@ -72,6 +88,17 @@ fallback:
 	return
 }
 
+// Version of an installation as shown on the status page
+func blenderVersion(installation blender.Installation) string {
+	if !installation.Available {
+		return "not available"
+	}
+	if installation.BuildHash == "" {
+		return installation.Version
+	}
+	return installation.Version + " (" + installation.BuildHash + ")"
+}
+
 // Return information about current node as JSON
 func (ctx *RouteCtx) getInfoHandler(writer http.ResponseWriter, req *http.Request) {
 	RespondJson(writer, ctx.Node.NodeInfoMap())
@@ -80,7 +107,7 @@ func (ctx *RouteCtx) getInfoHandler(writer http.ResponseWriter, req *http.Reques
 // Retrieve a list of scenes stored in the scene index
 func (ctx *RouteCtx) getScenesHandler(writer http.ResponseWriter, req *http.Request) {
 	writer.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(writer).Encode(scenes.SceneIndexResponseFromIndex(&ctx.SceneStore))
+	json.NewEncoder(writer).Encode(scenes.SceneIndexResponseFromIndex(ctx.SceneStore))
 }
 
 // Upload compressed scene file and store it in the scene index
@@ -147,82 +174,386 @@ func (ctx *RouteCtx) postUploadHandler(writer http.ResponseWriter, req *http.Req
 	})
 }
 
-// Start a rendering job on a previously uploaded scene
+// Queue a rendering job on a previously uploaded scene
 func (ctx *RouteCtx) postRenderHandler(writer http.ResponseWriter, req *http.Request) {
-	if !ctx.Node.State.RenderLock.TryLock() {
-		http.Error(writer, "Aether node is currently rendering.", http.StatusServiceUnavailable)
-		logrus.Debug("Refusing incoming rendering request (Renderer is busy).")
-		return
-	}
-
 	var request render.RenderRequest
 	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
 		http.Error(writer, "Could not parse JSON render request", http.StatusBadRequest)
 		logrus.Debugf("Could not parse JSON render request: %s\n", err)
+		return
+	}
 
-		ctx.Node.State.RenderLock.Unlock()
+	if request.ID == nil {
+		http.Error(writer, "Expected required field \"id\" as part of render request", http.StatusBadRequest)
+		logrus.Debugf("Render request did not contain required field \"id\"\n")
 		return
 	}
 
-	if request.FrameStart == nil {
-		http.Error(writer, "Expected required field \"frame_start\" as part of render request", http.StatusBadRequest)
-		logrus.Debugf("Render request did not contain required field \"frame_start\"\n")
+	if err := request.ValidateFrames(); err != nil {
+		http.Error(writer, "Invalid frame selection: "+err.Error(), http.StatusBadRequest)
+		logrus.Debugf("Render request has an invalid frame selection: %s\n", err)
+		return
+	}
 
-		ctx.Node.State.RenderLock.Unlock()
+	if request.OutputFormat != nil && !render.IsValidOutputFormat(*request.OutputFormat) {
+		http.Error(writer, "Unsupported \"output_format\", expected one of: "+strings.Join(render.OutputFormats, ", "), http.StatusBadRequest)
+		logrus.Debugf("Render request has unsupported output format \"%s\"\n", *request.OutputFormat)
 		return
 	}
 
-	if request.FrameEnd == nil {
-		http.Error(writer, "Expected required field \"frame_end\" as part of render request", http.StatusBadRequest)
-		logrus.Debugf("Render request did not contain required field \"frame_end\"\n")
+	if request.Engine != nil && !render.IsValidEngine(*request.Engine) {
+		http.Error(writer, "Unsupported \"engine\", expected one of: "+strings.Join(render.Engines, ", "), http.StatusBadRequest)
+		logrus.Debugf("Render request has unsupported engine \"%s\"\n", *request.Engine)
+		return
+	}
 
-		ctx.Node.State.RenderLock.Unlock()
+	// Requests without "blender" use the default installation, which might not be available either
+	var selector string
+	if request.Blender != nil {
+		selector = *request.Blender
+	}
+	if _, err := ctx.Node.Blenders.Resolve(selector); err != nil {
+		http.Error(writer, "Unsupported \"blender\": "+err.Error(), http.StatusBadRequest)
+		logrus.Debugf("Render request selects an unavailable blender: %s\n", err)
 		return
 	}
 
-	if request.ID == nil {
-		http.Error(writer, "Expected required field \"id\" as part of render request", http.StatusBadRequest)
-		logrus.Debugf("Render request did not contain required field \"id\"\n")
+	if request.Scene != nil && strings.TrimSpace(*request.Scene) == "" {
+		http.Error(writer, "\"scene\" must not be empty", http.StatusBadRequest)
+		logrus.Debugf("Render request has an empty scene name\n")
+		return
+	}
 
-		ctx.Node.State.RenderLock.Unlock()
+	if request.MaxDuration != nil && *request.MaxDuration <= 0 {
+		http.Error(writer, "\"max_duration\" must be a positive number of seconds", http.StatusBadRequest)
+		logrus.Debugf("Render request has a non-positive maximum duration (%d)\n", *request.MaxDuration)
 		return
 	}
 
-	var scene *state.SceneMetadata
+	if err := render.ValidateOverrides(request.Overrides); err != nil {
+		http.Error(writer, "Invalid \"overrides\": "+err.Error(), http.StatusBadRequest)
+		logrus.Debugf("Render request has invalid overrides: %s\n", err)
+		return
+	}
 
-	if scene = ctx.SceneStore.FindSceneById(*request.ID); scene == nil {
+	scene := ctx.SceneStore.FindSceneById(*request.ID)
+	if scene == nil {
 		http.Error(writer, "A scene with this ID does not exist", http.StatusBadRequest)
-		logrus.Debug("Could not find a scene with the requested ID (%s)\n", request.ID)
+		logrus.Debugf("Could not find a scene with the requested ID (%s)\n", request.ID)
+		return
+	}
 
-		ctx.Node.State.RenderLock.Unlock()
+	var requestedFile string
+	if request.BlendFile != nil {
+		requestedFile = *request.BlendFile
+	}
+	if _, err := scene.SelectBlendFile(requestedFile); err != nil {
+		http.Error(writer, "Could not select the *.blend file to render: "+err.Error(), http.StatusBadRequest)
+		logrus.Debugf("Could not select the *.blend file of scene %s: %s\n", scene.ID, err)
 		return
 	}
 
-	// This is where we create the RenderState for the first time
-	ctx.Node.State.RendererState = &state.RendererState{Scene: *scene, Request: request, CurrentFrame: 0, FramePercent: 0.0}
+	jobId, _ := uuid.NewRandom()
+	job := state.Job{
+		ID:       jobId,
+		Request:  request,
+		Status:   state.JobQueued,
+		QueuedAt: time.Now().UnixNano(),
+	}
+
+	ctx.Jobs.UpdateJob(job, ctx.Config)
+	position := ctx.Queue.Enqueue(job, ctx.Config)
+
+	logrus.Infof("Queued job %s for scene %s (position %d)\n", jobId, request.ID, position)
 
-	err := rendering.InitializeRenderProcess(ctx.Config, &ctx.Node.State, &request)
+	// Workspace preparation and rendering happen in the background; Progress is reported via /status
+	writer.Header().Set("Location", "/jobs/"+jobId.String())
+	RespondJsonStatus(writer, http.StatusAccepted, map[string]interface{}{
+		"id":       jobId,
+		"status":   state.JobQueued,
+		"position": position,
+	})
+}
+
+// Retrieve the list of jobs waiting to be rendered
+func (ctx *RouteCtx) getQueueHandler(writer http.ResponseWriter, req *http.Request) {
+	RespondJson(writer, map[string]interface{}{
+		"jobs": ctx.Queue.Pending(),
+	})
+}
+
+// Cancel a queued or running job. Running jobs keep the frames rendered so far if "keep_partial" is set.
+func (ctx *RouteCtx) postCancelJobHandler(writer http.ResponseWriter, req *http.Request) {
+	jobId, err := uuid.Parse(req.PathValue("id"))
 	if err != nil {
-		http.Error(writer, "Could not invoke renderer", http.StatusInternalServerError)
-		logrus.Debugf("Could not invoke renderer: %s\n", err)
+		http.Error(writer, "Invalid job ID", http.StatusBadRequest)
+		logrus.Debugf("Could not parse job ID: %s\n", err)
+		return
+	}
+
+	keepPartial := req.URL.Query().Get("keep_partial") == "true"
 
-		ctx.Node.State.RendererState = nil
-		ctx.Node.State.RenderLock.Unlock()
+	if ctx.Queue.Remove(jobId, ctx.Config) {
+		if job := ctx.Jobs.FindJobById(jobId); job != nil {
+			job.Status = state.JobCancelled
+			job.FinishedAt = time.Now().UnixNano()
+			ctx.Jobs.UpdateJob(*job, ctx.Config)
+		}
+
+		logrus.Infof("Removed job %s from the queue\n", jobId)
+		RespondJson(writer, map[string]interface{}{
+			"id":     jobId,
+			"status": "CANCELLED",
+		})
+		return
+	}
+
+	renderState := ctx.Node.State.Renderer()
+	if renderState == nil || renderState.JobID != jobId {
+		http.Error(writer, "A queued or running job with this ID does not exist", http.StatusNotFound)
+		logrus.Debugf("Could not find a queued or running job with the requested ID (%s)\n", jobId)
+		return
+	}
+
+	err = rendering.CancelRender(&ctx.Node.State, keepPartial)
+	if err != nil {
+		http.Error(writer, "Could not cancel job", http.StatusInternalServerError)
+		logrus.Errorf("Could not cancel job %s: %s\n", jobId, err)
+		return
+	}
+
+	RespondJson(writer, map[string]interface{}{
+		"id":           jobId,
+		"status":       "CANCELLED",
+		"keep_partial": keepPartial,
+	})
+}
+
+// Find the running job addressed by the request path
+func (ctx *RouteCtx) runningJobFromPath(writer http.ResponseWriter, req *http.Request) bool {
+	jobId, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		http.Error(writer, "Invalid job ID", http.StatusBadRequest)
+		logrus.Debugf("Could not parse job ID: %s\n", err)
+		return false
+	}
+
+	renderState := ctx.Node.State.Renderer()
+	if renderState == nil || renderState.JobID != jobId {
+		http.Error(writer, "A running job with this ID does not exist", http.StatusNotFound)
+		logrus.Debugf("Could not find a running job with the requested ID (%s)\n", jobId)
+		return false
+	}
+
+	return true
+}
+
+// Suspend the Blender process of a running job
+func (ctx *RouteCtx) postPauseJobHandler(writer http.ResponseWriter, req *http.Request) {
+	if !ctx.runningJobFromPath(writer, req) {
+		return
+	}
+
+	if err := rendering.PauseRender(&ctx.Node.State); err != nil {
+		http.Error(writer, "Could not pause job: "+err.Error(), http.StatusConflict)
+		logrus.Debugf("Could not pause job: %s\n", err)
 		return
 	}
 
-	writer.Write([]byte("OK"))
-	// Note: RenderLock is still locked if InitializeRenderProcess succeeded!
+	RespondJson(writer, map[string]interface{}{
+		"id":     req.PathValue("id"),
+		"status": "PAUSED",
+	})
+}
+
+// Continue the Blender process of a paused job, or queue an interrupted job to render its missing frames
+func (ctx *RouteCtx) postResumeJobHandler(writer http.ResponseWriter, req *http.Request) {
+	job := ctx.jobFromPath(writer, req)
+	if job == nil {
+		return
+	}
+
+	if job.Status == state.JobInterrupted {
+		position := rendering.RequeueInterruptedJob(*job, ctx.Config, ctx.Queue, ctx.Jobs, false)
+		logrus.Infof("Queued interrupted job %s for resumption (position %d)\n", job.ID, position)
+
+		RespondJsonStatus(writer, http.StatusAccepted, map[string]interface{}{
+			"id":       job.ID,
+			"status":   state.JobQueued,
+			"position": position,
+		})
+		return
+	}
+
+	if !ctx.runningJobFromPath(writer, req) {
+		return
+	}
+
+	if err := rendering.ResumeRender(&ctx.Node.State); err != nil {
+		http.Error(writer, "Could not resume job: "+err.Error(), http.StatusConflict)
+		logrus.Debugf("Could not resume job: %s\n", err)
+		return
+	}
+
+	RespondJson(writer, map[string]interface{}{
+		"id":     req.PathValue("id"),
+		"status": "RENDERING",
+	})
 }
 
 // Retrieve information about the current rendering job
 func (ctx *RouteCtx) getStatusHandler(writer http.ResponseWriter, req *http.Request) {
 	writer.Header().Set("Content-Type", "application/json")
-	renderState := ctx.Node.State.RendererState
-	_ = json.NewEncoder(writer).Encode(progress.StatusResponseFromRenderState(renderState))
+	snapshot := ctx.Node.State.Snapshot()
+	response := progress.StatusResponseFromRenderState(snapshot.RendererState)
+	response.State = snapshot.Phase
+	response.StateSince = snapshot.PhaseSince
+	response.Transitions = snapshot.Transitions
+	response.QueueLength = len(ctx.Queue.Pending())
+	if lastJob := snapshot.LastJob; lastJob != nil {
+		lastJobResponse := jobs.JobResponseFromJob(lastJob)
+		response.LastJob = &lastJobResponse
+	}
+	_ = json.NewEncoder(writer).Encode(response)
 	return
 }
 
+// Retrieve the history of all jobs known to this node
+func (ctx *RouteCtx) getJobsHandler(writer http.ResponseWriter, req *http.Request) {
+	writer.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(writer).Encode(jobs.JobIndexResponseFromJobs(ctx.Jobs.AllJobs()))
+}
+
+// Find the job addressed by the request path in the job index
+func (ctx *RouteCtx) jobFromPath(writer http.ResponseWriter, req *http.Request) *state.Job {
+	jobId, err := uuid.Parse(req.PathValue("id"))
+	if err != nil {
+		http.Error(writer, "Invalid job ID", http.StatusBadRequest)
+		logrus.Debugf("Could not parse job ID: %s\n", err)
+		return nil
+	}
+
+	job := ctx.Jobs.FindJobById(jobId)
+	if job == nil {
+		http.Error(writer, "A job with this ID does not exist", http.StatusNotFound)
+		logrus.Debugf("Could not find a job with the requested ID (%s)\n", jobId)
+		return nil
+	}
+
+	return job
+}
+
+// Retrieve a single job record
+func (ctx *RouteCtx) getJobHandler(writer http.ResponseWriter, req *http.Request) {
+	job := ctx.jobFromPath(writer, req)
+	if job == nil {
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(writer).Encode(jobs.JobResponseFromJob(job))
+}
+
+// Retrieve the render result of a given job
+func (ctx *RouteCtx) getJobResultHandler(writer http.ResponseWriter, req *http.Request) {
+	job := ctx.jobFromPath(writer, req)
+	if job == nil {
+		return
+	}
+
+	if job.OutputPath == "" {
+		http.Error(writer, "This job does not have a render result", http.StatusNotFound)
+		logrus.Debugf("Job %s does not have a render result\n", job.ID)
+		return
+	}
+
+	serveResultFile(writer, req, job)
+}
+
+// Retrieve the complete Blender output of a job. With "follow=true", new output is streamed until the job has finished.
+func (ctx *RouteCtx) getJobLogHandler(writer http.ResponseWriter, req *http.Request) {
+	job := ctx.jobFromPath(writer, req)
+	if job == nil {
+		return
+	}
+
+	if job.LogPath == "" {
+		http.Error(writer, "This job has not been started yet", http.StatusNotFound)
+		logrus.Debugf("Job %s does not have a log yet\n", job.ID)
+		return
+	}
+
+	if req.URL.Query().Get("follow") == "true" {
+		followLog(writer, req, job.LogPath, func() bool {
+			current := ctx.Jobs.FindJobById(job.ID)
+			return current == nil || current.IsFinished()
+		})
+		return
+	}
+
+	f, err := os.Open(job.LogPath)
+	if os.IsNotExist(err) {
+		http.Error(writer, "Blender has not produced any output for this job", http.StatusNotFound)
+		logrus.Debugf("Log of job %s does not exist: %s\n", job.ID, job.LogPath)
+		return
+	}
+	if err != nil {
+		http.Error(writer, "Could not open log for reading", http.StatusInternalServerError)
+		logrus.Debugf("Could not open log for reading (%s): %s\n", job.LogPath, err)
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		http.Error(writer, "Could not stat log", http.StatusInternalServerError)
+		logrus.Debugf("Could not stat log (%s): %s\n", job.LogPath, err)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	http.ServeContent(writer, req, filepath.Base(job.LogPath), info.ModTime(), f)
+}
+
+// Retrieve the crash report Blender wrote when it crashed while rendering a job
+func (ctx *RouteCtx) getJobCrashReportHandler(writer http.ResponseWriter, req *http.Request) {
+	job := ctx.jobFromPath(writer, req)
+	if job == nil {
+		return
+	}
+
+	if job.CrashReport == "" {
+		http.Error(writer, "This job does not have a crash report", http.StatusNotFound)
+		logrus.Debugf("Job %s does not have a crash report\n", job.ID)
+		return
+	}
+
+	f, err := os.Open(job.CrashReport)
+	if os.IsNotExist(err) {
+		http.Error(writer, "The crash report no longer exists", http.StatusNotFound)
+		logrus.Debugf("Crash report of job %s does not exist: %s\n", job.ID, job.CrashReport)
+		return
+	}
+	if err != nil {
+		http.Error(writer, "Could not open crash report for reading", http.StatusInternalServerError)
+		logrus.Debugf("Could not open crash report for reading (%s): %s\n", job.CrashReport, err)
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		http.Error(writer, "Could not stat crash report", http.StatusInternalServerError)
+		logrus.Debugf("Could not stat crash report (%s): %s\n", job.CrashReport, err)
+		return
+	}
+
+	filename := filepath.Base(job.CrashReport)
+	writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	writer.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	http.ServeContent(writer, req, filename, info.ModTime(), f)
+}
+
 // Retrieve the last render result of a given scene
 func (ctx *RouteCtx) getRenderResult(writer http.ResponseWriter, req *http.Request) {
 	var request id.IDRequest
@@ -233,11 +564,23 @@ func (ctx *RouteCtx) getRenderResult(writer http.ResponseWriter, req *http.Reque
 		return
 	}
 
-	filename := request.ID.String() + ".zip"
-	path := filepath.Join(ctx.Config.Data.OutputDirectory, filename)
+	job := ctx.Jobs.FindLatestResult(request.ID)
+	if job == nil {
+		http.Error(writer, "A last render result does not exist for this scene", http.StatusNotFound)
+		logrus.Debugf("Render result does not exist for scene: %s\n", request.ID)
+		return
+	}
+
+	logrus.Debugf("Returning render result of job %s for scene: %s\n", job.ID, request.ID)
+	serveResultFile(writer, req, job)
+}
+
+func serveResultFile(writer http.ResponseWriter, req *http.Request, job *state.Job) {
+	path := job.OutputPath
+	filename := filepath.Base(path)
 
 	if _, err := os.Stat(path); os.IsNotExist(err) {
-		http.Error(writer, "A last render result does not exist for this scene", http.StatusNotFound)
+		http.Error(writer, "The render result no longer exists", http.StatusNotFound)
 		logrus.Debugf("Render result does not exist: %s\n", path)
 		return
 	}
@@ -257,8 +600,6 @@ func (ctx *RouteCtx) getRenderResult(writer http.ResponseWriter, req *http.Reque
 		return
 	}
 
-	logrus.Debugf("Returning render result for scene: %s\n", request.ID)
-
 	writer.Header().Set("Content-Type", "application/zip")
 	writer.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
 
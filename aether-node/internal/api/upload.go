@@ -4,14 +4,15 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"node/internal/archive"
 	"node/internal/state"
 	"node/internal/util"
 	"os"
-	"strings"
 
 	"github.com/dustin/go-humanize"
 	"github.com/google/uuid"
@@ -35,17 +36,16 @@ func acquireMetadata(jsonMeta string, writer http.ResponseWriter) *state.SceneMe
 	return &metadata
 }
 
-func processFile(ctx *RouteCtx, fileSize int64, filename string, file multipart.File, metadata *state.SceneMetadata, writer http.ResponseWriter) bool {
-	// Aether only supports *.zip files
-	if !strings.HasSuffix(filename, ".zip") {
-		http.Error(writer, "The file must be a \"*.zip\" file", http.StatusBadRequest)
-		logrus.Debugf("Rejecting file \"%s\": Not a *.zip file.", filename)
-		return false
+func removeTempFile(path string) {
+	if err := os.Remove(path); err != nil {
+		logrus.Errorf("Could not remove temp file \"%s\": %s\n", path, err)
 	}
+}
 
-	// Generate a UUID file name to uniquely identify the file
+func processFile(ctx *RouteCtx, fileSize int64, filename string, file multipart.File, metadata *state.SceneMetadata, writer http.ResponseWriter) bool {
+	// Generate a UUID file name to uniquely identify the file; The extension is added once the format is known
 	id, _ := uuid.NewRandom()
-	randomFilename := id.String() + ".zip"
+	randomFilename := id.String()
 
 	logrus.Debugf("File \"%s\" is now \"%s\"", filename, randomFilename)
 
@@ -93,7 +93,43 @@ func processFile(ctx *RouteCtx, fileSize int64, filename string, file multipart.
 
 	logrus.Debugf("SHA256 Checksums match!\n")
 
+	// Detect the format by content and list the *.blend files, so render requests can be checked and unsafe archives refused before any job extracts them
+	format, entries, err := archive.Inspect(tmpFilePath, ctx.Config.ExtractionLimits())
+	if err != nil {
+		var rejected *archive.RejectedError
+		switch {
+		case errors.Is(err, archive.ErrUnknownFormat):
+			http.Error(writer, "Unsupported file: "+err.Error(), http.StatusUnsupportedMediaType)
+		case errors.Is(err, archive.ErrTooLarge) || errors.Is(err, archive.ErrTooManyFiles):
+			http.Error(writer, "Archive rejected: "+err.Error(), http.StatusRequestEntityTooLarge)
+		case errors.As(err, &rejected):
+			http.Error(writer, "Archive rejected: "+err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(writer, "Could not read the uploaded file", http.StatusBadRequest)
+		}
+		logrus.Debugf("Could not inspect the contents of \"%s\": %s\n", tmpFilePath, err)
+		removeTempFile(tmpFilePath)
+		return false
+	}
+	metadata.BlendFiles = archive.BlendFiles(entries)
+
+	// A bare *.blend file is always stored under the same name, whatever it was called by the client
+	if format == archive.FormatBlend {
+		metadata.MainFile = ""
+	}
+
+	if metadata.MainFile != "" {
+		metadata.MainFile = state.CleanScenePath(metadata.MainFile)
+	}
+	if _, err := metadata.SelectBlendFile(""); err != nil && err != state.ErrAmbiguousBlendFile {
+		http.Error(writer, "Invalid scene: "+err.Error(), http.StatusBadRequest)
+		logrus.Debugf("Rejecting scene \"%s\": %s\n", filename, err)
+		removeTempFile(tmpFilePath)
+		return false
+	}
+
 	// The checksum is correct; Move file to scenes directory
+	randomFilename += format.Extension()
 	scenePath := ctx.Config.Data.ScenesDirectory + "/" + randomFilename
 	err = os.Rename(tmpFilePath, scenePath)
 	if err != nil {
@@ -0,0 +1,137 @@
+package archive
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Limits protect the workspace from archives that expand far beyond their own size. Zero disables a limit.
+type Limits struct {
+	// Total uncompressed size of all entries in bytes
+	MaxSize uint64
+	// Number of files
+	MaxFiles int
+	// Uncompressed size of a single entry divided by its compressed size
+	MaxRatio float64
+}
+
+// A file in an archive as declared by the archive itself
+type Entry struct {
+	Name           string
+	Size           uint64
+	CompressedSize uint64
+}
+
+var (
+	ErrPathTraversal    = errors.New("the entry escapes the destination directory")
+	ErrAbsolutePath     = errors.New("the entry has an absolute path")
+	ErrSymlink          = errors.New("the entry is a symbolic or hard link, which is not supported")
+	ErrUnsupportedEntry = errors.New("the entry is neither a regular file nor a directory")
+	ErrSizeMismatch     = errors.New("the entry is larger than the archive declares")
+	ErrTooLarge         = errors.New("the archive exceeds the maximum uncompressed size")
+	ErrTooManyFiles     = errors.New("the archive exceeds the maximum number of files")
+	ErrCompressionRatio = errors.New("the compression ratio exceeds the maximum")
+)
+
+// An archive was refused because of one of its entries. Unwraps to one of the Err* values above.
+type RejectedError struct {
+	Entry  string
+	Reason error
+}
+
+func (e *RejectedError) Error() string {
+	if e.Entry == "" {
+		return e.Reason.Error()
+	}
+	return fmt.Sprintf("%s: %s", e.Entry, e.Reason)
+}
+
+func (e *RejectedError) Unwrap() error {
+	return e.Reason
+}
+
+func rejected(entry string, reason error) error {
+	return &RejectedError{Entry: entry, Reason: reason}
+}
+
+// Entries that are packaging leftovers of macOS and never extracted
+func isIgnored(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasPrefix(lower, "__macosx/") || strings.HasSuffix(lower, ".ds_store")
+}
+
+// Normalize an entry name to a relative slash separated path, refusing absolute paths and paths leaving the root
+func cleanEntryName(name string) (string, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+
+	// "/etc/passwd", "C:/Windows" and "//server/share" are all absolute somewhere
+	if path.IsAbs(name) || filepath.IsAbs(name) || filepath.VolumeName(name) != "" || (len(name) >= 2 && name[1] == ':') {
+		return "", rejected(name, ErrAbsolutePath)
+	}
+
+	cleaned := path.Clean(name)
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", rejected(name, ErrPathTraversal)
+	}
+	return cleaned, nil
+}
+
+// Location of an entry below dst. Fails if the entry would end up outside of dst.
+func entryPath(dst string, name string) (string, error) {
+	cleaned, err := cleanEntryName(name)
+	if err != nil {
+		return "", err
+	}
+
+	target := filepath.Join(dst, filepath.FromSlash(cleaned))
+	rel, err := filepath.Rel(dst, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", rejected(name, ErrPathTraversal)
+	}
+	return target, nil
+}
+
+// Check the entries an archive declares against the limits.
+// Formats without per entry sizes pass the size of the whole archive as compressedSize, else it is 0.
+func checkEntries(entries []Entry, compressedSize uint64, limits Limits) error {
+	if limits.MaxFiles > 0 && len(entries) > limits.MaxFiles {
+		return rejected("", fmt.Errorf("%w (%d)", ErrTooManyFiles, limits.MaxFiles))
+	}
+
+	var total uint64
+	for _, entry := range entries {
+		total += entry.Size
+		if limits.MaxSize > 0 && total > limits.MaxSize {
+			return rejected("", fmt.Errorf("%w (%d bytes)", ErrTooLarge, limits.MaxSize))
+		}
+
+		if limits.MaxRatio > 0 && entry.Size > 0 && entry.CompressedSize > 0 {
+			ratio := float64(entry.Size) / float64(entry.CompressedSize)
+			if ratio > limits.MaxRatio {
+				return rejected(entry.Name, fmt.Errorf("%w (%.0f:1)", ErrCompressionRatio, limits.MaxRatio))
+			}
+		}
+	}
+
+	if limits.MaxRatio > 0 && compressedSize > 0 && float64(total)/float64(compressedSize) > limits.MaxRatio {
+		return rejected("", fmt.Errorf("%w (%.0f:1)", ErrCompressionRatio, limits.MaxRatio))
+	}
+	return nil
+}
+
+// Paths of all *.blend files among the entries, sorted
+func BlendFiles(entries []Entry) []string {
+	files := []string{}
+	for _, entry := range entries {
+		if strings.ToLower(path.Ext(entry.Name)) == ".blend" {
+			files = append(files, entry.Name)
+		}
+	}
+
+	sort.Strings(files)
+	return files
+}
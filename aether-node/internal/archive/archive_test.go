@@ -0,0 +1,110 @@
+package archive
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCleanEntryName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		err  error
+	}{
+		{name: "scene.blend", want: "scene.blend"},
+		{name: "./shots/sh010.blend", want: "shots/sh010.blend"},
+		{name: "shots/../scene.blend", want: "scene.blend"},
+		{name: "shots\\sh010.blend", want: "shots/sh010.blend"},
+		{name: "..", err: ErrPathTraversal},
+		{name: "../evil", err: ErrPathTraversal},
+		{name: "../../etc/cron.d/evil", err: ErrPathTraversal},
+		{name: "shots/../../evil", err: ErrPathTraversal},
+		{name: "..\\..\\evil", err: ErrPathTraversal},
+		{name: "/etc/passwd", err: ErrAbsolutePath},
+		{name: "\\etc\\passwd", err: ErrAbsolutePath},
+		{name: "C:\\Windows\\System32\\evil.dll", err: ErrAbsolutePath},
+		{name: "C:/Windows/evil", err: ErrAbsolutePath},
+		{name: "C:evil", err: ErrAbsolutePath},
+		{name: "\\\\server\\share\\evil", err: ErrAbsolutePath},
+		{name: "//server/share/evil", err: ErrAbsolutePath},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := cleanEntryName(test.name)
+			if test.err != nil {
+				if !errors.Is(err, test.err) {
+					t.Fatalf("got %q, %v, want error %v", got, err, test.err)
+				}
+				var rejected *RejectedError
+				if !errors.As(err, &rejected) || rejected.Entry == "" {
+					t.Errorf("error %v does not name the entry", err)
+				}
+				return
+			}
+			if err != nil || got != test.want {
+				t.Errorf("got %q, %v, want %q", got, err, test.want)
+			}
+		})
+	}
+}
+
+func TestEntryPath(t *testing.T) {
+	dst := t.TempDir()
+
+	path, err := entryPath(dst, "shots/sh010.blend")
+	if err != nil || path != filepath.Join(dst, "shots", "sh010.blend") {
+		t.Errorf("got %q, %v", path, err)
+	}
+
+	for _, name := range []string{"../evil", "shots/../../evil", "/etc/passwd", "C:\\evil"} {
+		if path, err := entryPath(dst, name); err == nil {
+			t.Errorf("%q resolved to %q", name, path)
+		}
+	}
+}
+
+func TestCheckEntries(t *testing.T) {
+	entries := []Entry{
+		{Name: "a.blend", Size: 400, CompressedSize: 100},
+		{Name: "tex/b.png", Size: 600, CompressedSize: 600},
+	}
+
+	tests := []struct {
+		name           string
+		limits         Limits
+		compressedSize uint64
+		err            error
+	}{
+		{name: "no limits", limits: Limits{}},
+		{name: "within limits", limits: Limits{MaxSize: 1000, MaxFiles: 2, MaxRatio: 4}},
+		{name: "too many files", limits: Limits{MaxFiles: 1}, err: ErrTooManyFiles},
+		{name: "too large", limits: Limits{MaxSize: 999}, err: ErrTooLarge},
+		{name: "entry ratio", limits: Limits{MaxRatio: 3.9}, err: ErrCompressionRatio},
+		{name: "archive ratio", limits: Limits{MaxRatio: 9}, compressedSize: 100, err: ErrCompressionRatio},
+		{name: "archive ratio within limit", limits: Limits{MaxRatio: 10}, compressedSize: 100},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkEntries(entries, test.compressedSize, test.limits)
+			if test.err == nil && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if test.err != nil && !errors.Is(err, test.err) {
+				t.Errorf("got %v, want %v", err, test.err)
+			}
+		})
+	}
+}
+
+func TestBlendFiles(t *testing.T) {
+	entries := []Entry{{Name: "shots/sh020.blend"}, {Name: "tex/wood.png"}, {Name: "LIB.BLEND"}, {Name: "shots/sh010.blend"}, {Name: "sh010.blend1"}}
+
+	want := []string{"LIB.BLEND", "shots/sh010.blend", "shots/sh020.blend"}
+	if got := BlendFiles(entries); !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
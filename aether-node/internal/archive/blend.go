@@ -0,0 +1,48 @@
+package archive
+
+import (
+	"node/internal/util"
+	"os"
+	"path/filepath"
+)
+
+// Name of a bare *.blend upload within its workspace
+const BlendFileName = "scene.blend"
+
+// A bare *.blend file is a scene with a single file. Compressed ones are kept as they are, Blender reads them directly.
+func inspectBlend(src string, limits Limits) ([]Entry, error) {
+	info, err := os.Stat(src)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []Entry{{Name: BlendFileName, Size: uint64(info.Size())}}
+	return entries, checkEntries(entries, 0, limits)
+}
+
+// Copy a bare *.blend file into dst
+func extractBlend(src string, dst string, limits Limits, onProgress func(util.ExtractProgress)) error {
+	entries, err := inspectBlend(src, limits)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	progress := util.ExtractProgress{FilesTotal: 1, BytesTotal: entries[0].Size}
+	onProgress(progress)
+
+	counter := &progressWriter{progress: &progress, onProgress: onProgress}
+	err = extractFile(filepath.Join(dst, BlendFileName), file, BlendFileName, entries[0].Size, counter, limits)
+	if err != nil {
+		return err
+	}
+
+	progress.FilesDone++
+	onProgress(progress)
+	return nil
+}
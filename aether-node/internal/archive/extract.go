@@ -0,0 +1,96 @@
+package archive
+
+import (
+	"io"
+	"node/internal/util"
+	"os"
+	"path/filepath"
+)
+
+// Validate a scene file without extracting it and return its format and files
+func Inspect(src string, limits Limits) (Format, []Entry, error) {
+	format, err := Detect(src)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var entries []Entry
+	switch format {
+	case FormatZip:
+		entries, err = inspectZip(src, limits)
+	case FormatBlend:
+		entries, err = inspectBlend(src, limits)
+	default:
+		entries, err = inspectTar(src, format, limits)
+	}
+	return format, entries, err
+}
+
+// Extract a scene file of any supported format into dst after validating it. onProgress (optional) is called as files are extracted.
+// The sizes declared by the archive are not trusted; Extraction stops as soon as the limits are exceeded.
+func Extract(src string, dst string, limits Limits, onProgress func(util.ExtractProgress)) error {
+	format, err := Detect(src)
+	if err != nil {
+		return err
+	}
+
+	if onProgress == nil {
+		onProgress = func(util.ExtractProgress) {}
+	}
+
+	switch format {
+	case FormatZip:
+		return extractZip(src, dst, limits, onProgress)
+	case FormatBlend:
+		return extractBlend(src, dst, limits, onProgress)
+	default:
+		return extractTar(src, dst, format, limits, onProgress)
+	}
+}
+
+// Writer that reports the number of bytes passing through it
+type progressWriter struct {
+	progress   *util.ExtractProgress
+	onProgress func(util.ExtractProgress)
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.progress.BytesDone += uint64(len(p))
+	w.onProgress(*w.progress)
+	return len(p), nil
+}
+
+// Write a single entry to path, refusing to write more than the archive declared or the limits allow
+func extractFile(path string, src io.Reader, name string, declaredSize uint64, counter *progressWriter, limits Limits) error {
+	// Create all directories leading up to the file
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return err
+	}
+
+	dstFile, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+	defer dstFile.Close()
+
+	remaining := declaredSize
+	if limits.MaxSize > 0 {
+		if counter.progress.BytesDone >= limits.MaxSize {
+			return rejected(name, ErrTooLarge)
+		}
+		remaining = min(remaining, limits.MaxSize-counter.progress.BytesDone)
+	}
+
+	written, err := io.Copy(io.MultiWriter(dstFile, counter), io.LimitReader(src, int64(remaining)+1))
+	if err != nil {
+		return err
+	}
+	if uint64(written) > remaining {
+		if uint64(written) > declaredSize {
+			return rejected(name, ErrSizeMismatch)
+		}
+		return rejected(name, ErrTooLarge)
+	}
+
+	return nil
+}
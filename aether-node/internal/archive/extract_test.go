@@ -0,0 +1,397 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"node/internal/util"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+type testFile struct {
+	name    string
+	content string
+	mode    fs.FileMode
+	// Target of links
+	link string
+	// Tar entry type, regular files by default
+	typeflag byte
+}
+
+var sceneFiles = []testFile{
+	{name: "shots/"},
+	{name: "shots/sh010.blend", content: "BLENDER-v402 shot"},
+	{name: "tex/wood.png", content: "png"},
+	{name: "__MACOSX/shots/._sh010.blend", content: "resource fork"},
+}
+
+func writeZip(t *testing.T, files []testFile) string {
+	t.Helper()
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+
+	for _, file := range files {
+		header := &zip.FileHeader{Name: file.name, Method: zip.Deflate}
+		mode := file.mode
+		if mode == 0 {
+			mode = 0644
+		}
+		if file.name[len(file.name)-1] == '/' {
+			mode |= fs.ModeDir
+		}
+		header.SetMode(mode)
+
+		entry, err := writer.CreateHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content := file.content
+		if mode&fs.ModeSymlink != 0 {
+			content = file.link
+		}
+		if _, err := entry.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return writeFile(t, "scene.zip", buffer.Bytes())
+}
+
+func writeTar(t *testing.T, format Format, files []testFile) string {
+	t.Helper()
+	var buffer bytes.Buffer
+	var stream io.WriteCloser
+
+	switch format {
+	case FormatTarGzip:
+		stream = gzip.NewWriter(&buffer)
+	case FormatTarZstd:
+		encoder, err := zstd.NewWriter(&buffer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stream = encoder
+	default:
+		stream = nopCloser{&buffer}
+	}
+
+	writer := tar.NewWriter(stream)
+	for _, file := range files {
+		header := &tar.Header{Name: file.name, Mode: 0644, Size: int64(len(file.content)), Typeflag: file.typeflag, Linkname: file.link}
+		switch {
+		case header.Typeflag == 0 && file.name[len(file.name)-1] == '/':
+			header.Typeflag = tar.TypeDir
+		case header.Typeflag == 0:
+			header.Typeflag = tar.TypeReg
+		}
+		if header.Typeflag != tar.TypeReg {
+			header.Size = 0
+		}
+
+		if err := writer.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Size > 0 {
+			if _, err := writer.Write([]byte(file.content)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return writeFile(t, "scene"+format.Extension(), buffer.Bytes())
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+func writeFile(t *testing.T, name string, content []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// Destination directory inside a parent, so escapes into the parent can be detected
+func extractionTarget(t *testing.T) (string, string) {
+	parent := t.TempDir()
+	dst := filepath.Join(parent, "workspace")
+	if err := os.Mkdir(dst, 0755); err != nil {
+		t.Fatal(err)
+	}
+	return parent, dst
+}
+
+func TestExtractFormats(t *testing.T) {
+	sources := map[Format]func(t *testing.T) string{
+		FormatZip:     func(t *testing.T) string { return writeZip(t, sceneFiles) },
+		FormatTar:     func(t *testing.T) string { return writeTar(t, FormatTar, sceneFiles) },
+		FormatTarGzip: func(t *testing.T) string { return writeTar(t, FormatTarGzip, sceneFiles) },
+		FormatTarZstd: func(t *testing.T) string { return writeTar(t, FormatTarZstd, sceneFiles) },
+	}
+
+	for format, source := range sources {
+		t.Run(string(format), func(t *testing.T) {
+			src := source(t)
+
+			detected, entries, err := Inspect(src, Limits{MaxSize: 1024, MaxFiles: 10, MaxRatio: 100})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if detected != format {
+				t.Errorf("detected %s", detected)
+			}
+			if files := BlendFiles(entries); len(files) != 1 || files[0] != "shots/sh010.blend" {
+				t.Errorf("blend files %v", files)
+			}
+
+			_, dst := extractionTarget(t)
+			var last util.ExtractProgress
+			err = Extract(src, dst, Limits{MaxSize: 1024, MaxFiles: 10, MaxRatio: 100}, func(progress util.ExtractProgress) {
+				last = progress
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			content, err := os.ReadFile(filepath.Join(dst, "shots", "sh010.blend"))
+			if err != nil || string(content) != "BLENDER-v402 shot" {
+				t.Errorf("got %q, %v", content, err)
+			}
+			if _, err := os.Stat(filepath.Join(dst, "__MACOSX")); !os.IsNotExist(err) {
+				t.Errorf("macOS metadata was extracted")
+			}
+			if last.FilesDone != 2 || last.FilesTotal != 2 || last.BytesDone != last.BytesTotal {
+				t.Errorf("final progress %+v", last)
+			}
+		})
+	}
+}
+
+func TestExtractBlend(t *testing.T) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	writer.Write([]byte("BLENDER-v279 compressed"))
+	writer.Close()
+
+	for name, content := range map[string][]byte{"plain": []byte("BLENDER-v402 plain"), "gzip": compressed.Bytes()} {
+		t.Run(name, func(t *testing.T) {
+			src := writeFile(t, "upload.blend", content)
+
+			format, entries, err := Inspect(src, Limits{})
+			if err != nil || format != FormatBlend || len(entries) != 1 || entries[0].Name != BlendFileName {
+				t.Fatalf("got %s, %v, %v", format, entries, err)
+			}
+
+			_, dst := extractionTarget(t)
+			if err := Extract(src, dst, Limits{}, nil); err != nil {
+				t.Fatal(err)
+			}
+
+			// Compressed files are kept as they are, Blender reads them itself
+			extracted, err := os.ReadFile(filepath.Join(dst, BlendFileName))
+			if err != nil || !bytes.Equal(extracted, content) {
+				t.Errorf("got %q, %v", extracted, err)
+			}
+		})
+	}
+}
+
+func TestDetectUnknownFormat(t *testing.T) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	writer.Write([]byte("just some text"))
+	writer.Close()
+
+	for name, content := range map[string][]byte{"text": []byte("hello"), "empty": nil, "gzip text": compressed.Bytes()} {
+		if _, err := Detect(writeFile(t, "upload", content)); !errors.Is(err, ErrUnknownFormat) {
+			t.Errorf("%s: got %v", name, err)
+		}
+	}
+}
+
+func TestExtractRejectsUnsafeEntries(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []testFile
+		err   error
+		// Tar entries only
+		tarOnly bool
+	}{
+		{name: "traversal", files: []testFile{{name: "../evil", content: "x"}}, err: ErrPathTraversal},
+		{name: "nested traversal", files: []testFile{{name: "shots/../../evil", content: "x"}}, err: ErrPathTraversal},
+		{name: "absolute", files: []testFile{{name: "/tmp/evil", content: "x"}}, err: ErrAbsolutePath},
+		{name: "drive letter", files: []testFile{{name: "C:\\evil", content: "x"}}, err: ErrAbsolutePath},
+		{name: "unc path", files: []testFile{{name: "\\\\server\\share\\evil", content: "x"}}, err: ErrAbsolutePath},
+		{name: "symlink", files: []testFile{{name: "link", mode: fs.ModeSymlink | 0777, link: "../../etc/passwd", typeflag: tar.TypeSymlink}}, err: ErrSymlink},
+		{name: "hard link", files: []testFile{{name: "link", link: "/etc/passwd", typeflag: tar.TypeLink}}, err: ErrSymlink, tarOnly: true},
+		{name: "device", files: []testFile{{name: "null", typeflag: tar.TypeChar}}, err: ErrUnsupportedEntry, tarOnly: true},
+	}
+
+	for _, test := range tests {
+		sources := map[string]func(t *testing.T) string{
+			"tar":    func(t *testing.T) string { return writeTar(t, FormatTar, test.files) },
+			"tar.gz": func(t *testing.T) string { return writeTar(t, FormatTarGzip, test.files) },
+		}
+		if !test.tarOnly {
+			sources["zip"] = func(t *testing.T) string { return writeZip(t, test.files) }
+		}
+
+		for format, source := range sources {
+			t.Run(test.name+"/"+format, func(t *testing.T) {
+				src := source(t)
+
+				if _, _, err := Inspect(src, Limits{}); !errors.Is(err, test.err) {
+					t.Errorf("Inspect: got %v, want %v", err, test.err)
+				}
+
+				parent, dst := extractionTarget(t)
+				if err := Extract(src, dst, Limits{}, nil); !errors.Is(err, test.err) {
+					t.Errorf("Extract: got %v, want %v", err, test.err)
+				}
+
+				// Nothing next to the workspace and nothing inside of it
+				if entries, _ := os.ReadDir(parent); len(entries) != 1 {
+					t.Errorf("files were written next to the workspace: %v", entries)
+				}
+				if entries, _ := os.ReadDir(dst); len(entries) > 0 {
+					t.Errorf("files were written to the workspace: %v", entries)
+				}
+			})
+		}
+	}
+}
+
+func TestExtractLimits(t *testing.T) {
+	zeros := string(make([]byte, 1<<20))
+
+	tests := []struct {
+		name   string
+		files  []testFile
+		limits Limits
+		err    error
+	}{
+		{name: "file count", files: []testFile{{name: "a", content: "a"}, {name: "b", content: "b"}, {name: "c", content: "c"}}, limits: Limits{MaxFiles: 2}, err: ErrTooManyFiles},
+		{name: "total size", files: []testFile{{name: "a", content: "0123456789"}, {name: "b", content: "0123456789"}}, limits: Limits{MaxSize: 15}, err: ErrTooLarge},
+		{name: "compression ratio", files: []testFile{{name: "bomb", content: zeros}}, limits: Limits{MaxRatio: 100}, err: ErrCompressionRatio},
+	}
+
+	for _, test := range tests {
+		sources := map[string]func(t *testing.T) string{
+			"zip":     func(t *testing.T) string { return writeZip(t, test.files) },
+			"tar.gz":  func(t *testing.T) string { return writeTar(t, FormatTarGzip, test.files) },
+			"tar.zst": func(t *testing.T) string { return writeTar(t, FormatTarZstd, test.files) },
+		}
+
+		for format, source := range sources {
+			t.Run(test.name+"/"+format, func(t *testing.T) {
+				src := source(t)
+
+				if _, _, err := Inspect(src, test.limits); !errors.Is(err, test.err) {
+					t.Errorf("Inspect: got %v, want %v", err, test.err)
+				}
+
+				_, dst := extractionTarget(t)
+				if err := Extract(src, dst, test.limits, nil); !errors.Is(err, test.err) {
+					t.Errorf("Extract: got %v, want %v", err, test.err)
+				}
+
+				// The limits do not apply when they are disabled
+				if _, _, err := Inspect(src, Limits{}); err != nil {
+					t.Errorf("Inspect without limits: %v", err)
+				}
+			})
+		}
+	}
+}
+
+// A zip entry whose header understates its size must not be written beyond the declared size
+func TestExtractZipSizeMismatch(t *testing.T) {
+	content := []byte("0123456789abcdefghij")
+
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+	header := &zip.FileHeader{
+		Name:               "scene.blend",
+		Method:             zip.Store,
+		CRC32:              crc32.ChecksumIEEE(content),
+		CompressedSize64:   uint64(len(content)),
+		UncompressedSize64: 4,
+	}
+	entry, err := writer.CreateRaw(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry.Write(content)
+	writer.Close()
+	src := writeFile(t, "scene.zip", buffer.Bytes())
+
+	// Only the extraction notices, the declared sizes are within the limits
+	if _, _, err := Inspect(src, Limits{MaxSize: 10}); err != nil {
+		t.Fatalf("Inspect: %v", err)
+	}
+
+	_, dst := extractionTarget(t)
+	if err := Extract(src, dst, Limits{MaxSize: 10}, nil); err == nil {
+		t.Fatal("extracted an entry larger than declared")
+	}
+
+	if info, err := os.Stat(filepath.Join(dst, "scene.blend")); err == nil && info.Size() > 4 {
+		t.Errorf("wrote %d bytes of an entry declared with 4", info.Size())
+	}
+}
+
+// Readers that deliver more than the archive declared are cut off, whatever the format checks itself
+func TestExtractFileLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		declared uint64
+		limits   Limits
+		done     uint64
+		err      error
+		written  int64
+	}{
+		{name: "as declared", declared: 20, written: 20},
+		{name: "larger than declared", declared: 4, err: ErrSizeMismatch, written: 5},
+		{name: "exceeds the total size", declared: 20, limits: Limits{MaxSize: 10}, err: ErrTooLarge, written: 11},
+		{name: "total size already reached", declared: 20, limits: Limits{MaxSize: 10}, done: 10, err: ErrTooLarge, written: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "entry")
+			progress := util.ExtractProgress{BytesDone: test.done}
+			counter := &progressWriter{progress: &progress, onProgress: func(util.ExtractProgress) {}}
+
+			err := extractFile(path, bytes.NewReader([]byte("0123456789abcdefghij")), "entry", test.declared, counter, test.limits)
+			if (test.err == nil && err != nil) || !errors.Is(err, test.err) {
+				t.Errorf("got %v, want %v", err, test.err)
+			}
+
+			// At most one byte beyond the allowed size is read to detect the overflow
+			if info, err := os.Stat(path); err == nil && info.Size() != test.written {
+				t.Errorf("wrote %d bytes, want %d", info.Size(), test.written)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Kind of a scene file, detected by its content
+type Format string
+
+const (
+	FormatZip     Format = "zip"
+	FormatTar     Format = "tar"
+	FormatTarGzip Format = "tar.gz"
+	FormatTarZstd Format = "tar.zst"
+	FormatBlend   Format = "blend"
+)
+
+var ErrUnknownFormat = errors.New("the file is neither a zip, tar, tar.gz or tar.zst archive nor a *.blend file")
+
+var (
+	zipMagic      = []byte("PK\x03\x04")
+	emptyZipMagic = []byte("PK\x05\x06")
+	gzipMagic     = []byte{0x1f, 0x8b}
+	zstdMagic     = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	blendMagic    = []byte("BLENDER")
+)
+
+// File name extension of stored scenes, e.g. ".tar.gz"
+func (format Format) Extension() string {
+	return "." + string(format)
+}
+
+// Determine the format of a scene file from its first bytes
+func Detect(src string) (Format, error) {
+	file, err := os.Open(src)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	header, err := readHeader(file)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case bytes.HasPrefix(header, zipMagic) || bytes.HasPrefix(header, emptyZipMagic):
+		return FormatZip, nil
+	case bytes.HasPrefix(header, blendMagic):
+		return FormatBlend, nil
+	case isTarHeader(header):
+		return FormatTar, nil
+	case bytes.HasPrefix(header, gzipMagic), bytes.HasPrefix(header, zstdMagic):
+		// Blender compresses *.blend files with gzip (before 3.0) and zstd as well, so look at what is inside
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return "", err
+		}
+		return detectCompressed(file, bytes.HasPrefix(header, gzipMagic))
+	}
+
+	return "", ErrUnknownFormat
+}
+
+func detectCompressed(file *os.File, isGzip bool) (Format, error) {
+	tarFormat := FormatTarZstd
+	if isGzip {
+		tarFormat = FormatTarGzip
+	}
+
+	reader, closeReader, err := decompress(file, tarFormat)
+	if err != nil {
+		return "", ErrUnknownFormat
+	}
+	defer closeReader()
+
+	header, err := readHeader(reader)
+	if err != nil {
+		return "", ErrUnknownFormat
+	}
+
+	switch {
+	case bytes.HasPrefix(header, blendMagic):
+		return FormatBlend, nil
+	case isTarHeader(header):
+		return tarFormat, nil
+	}
+	return "", ErrUnknownFormat
+}
+
+// Open the decompressed stream of a tarball. Plain tarballs are returned as they are.
+func decompress(file io.Reader, format Format) (io.Reader, func(), error) {
+	switch format {
+	case FormatTarGzip:
+		reader, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		return reader, func() { reader.Close() }, nil
+	case FormatTarZstd:
+		reader, err := zstd.NewReader(file, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, nil, err
+		}
+		return reader, reader.Close, nil
+	}
+	return file, func() {}, nil
+}
+
+// Up to one tar header block of the start of a stream
+func readHeader(reader io.Reader) ([]byte, error) {
+	header := make([]byte, 512)
+	n, err := io.ReadFull(reader, header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	return header[:n], nil
+}
+
+// Whether the block is a tar header, by its "ustar" magic or, for old V7 archives, its checksum
+func isTarHeader(header []byte) bool {
+	if len(header) < 512 {
+		return false
+	}
+	if bytes.Equal(header[257:262], []byte("ustar")) {
+		return true
+	}
+
+	// The checksum is the sum of all header bytes with the checksum field itself counted as spaces
+	field := bytes.Trim(header[148:156], " \x00")
+	if len(field) == 0 {
+		return false
+	}
+	var expected int64
+	for _, digit := range field {
+		if digit < '0' || digit > '7' {
+			return false
+		}
+		expected = expected*8 + int64(digit-'0')
+	}
+
+	var sum int64
+	for i, b := range header {
+		if i >= 148 && i < 156 {
+			b = ' '
+		}
+		sum += int64(b)
+	}
+	return sum == expected
+}
@@ -0,0 +1,149 @@
+package archive
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"node/internal/util"
+	"os"
+)
+
+// Validate a possibly compressed tarball without extracting it and return its files
+func inspectTar(src string, format Format, limits Limits) ([]Entry, error) {
+	file, err := os.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	stream, closeStream, err := decompress(file, format)
+	if err != nil {
+		return nil, err
+	}
+	defer closeStream()
+
+	reader := tar.NewReader(stream)
+	entries := []Entry{}
+	var total uint64
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		entry, err := tarEntry(header)
+		if err != nil {
+			return nil, err
+		}
+		if entry == nil {
+			continue
+		}
+		entries = append(entries, *entry)
+
+		// Tarballs have no index, so stop reading as soon as the limits are exceeded
+		total += entry.Size
+		if (limits.MaxFiles > 0 && len(entries) > limits.MaxFiles) || (limits.MaxSize > 0 && total > limits.MaxSize) {
+			break
+		}
+	}
+
+	return entries, checkEntries(entries, uint64(info.Size()), limits)
+}
+
+// The file a tar header describes, nil for entries that are not extracted
+func tarEntry(header *tar.Header) (*Entry, error) {
+	if isIgnored(header.Name) {
+		return nil, nil
+	}
+
+	name, err := cleanEntryName(header.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	switch header.Typeflag {
+	case tar.TypeDir, tar.TypeXGlobalHeader:
+		return nil, nil
+	case tar.TypeSymlink, tar.TypeLink:
+		return nil, rejected(header.Name, ErrSymlink)
+	case tar.TypeReg:
+		return &Entry{Name: name, Size: uint64(header.Size)}, nil
+	}
+	return nil, rejected(header.Name, ErrUnsupportedEntry)
+}
+
+// Extract a possibly compressed tarball into dst after validating it
+func extractTar(src string, dst string, format Format, limits Limits, onProgress func(util.ExtractProgress)) error {
+	entries, err := inspectTar(src, format, limits)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	stream, closeStream, err := decompress(file, format)
+	if err != nil {
+		return err
+	}
+	defer closeStream()
+
+	progress := util.ExtractProgress{FilesTotal: len(entries)}
+	for _, entry := range entries {
+		progress.BytesTotal += entry.Size
+	}
+	onProgress(progress)
+
+	counter := &progressWriter{progress: &progress, onProgress: onProgress}
+
+	bar := util.SyntheticProgressBar(len(entries), "UNTAR")
+	bar.RenderBlank()
+
+	reader := tar.NewReader(stream)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		entry, err := tarEntry(header)
+		if err != nil {
+			return err
+		}
+		if entry == nil {
+			continue
+		}
+
+		path, err := entryPath(dst, header.Name)
+		if err != nil {
+			return err
+		}
+
+		err = extractFile(path, reader, header.Name, entry.Size, counter, limits)
+		if err != nil {
+			return err
+		}
+
+		bar.Add(1)
+		progress.FilesDone++
+		onProgress(progress)
+	}
+
+	fmt.Println()
+
+	return nil
+}
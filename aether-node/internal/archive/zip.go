@@ -0,0 +1,102 @@
+package archive
+
+import (
+	"archive/zip"
+	"fmt"
+	"io/fs"
+	"node/internal/util"
+)
+
+// Validate a zip archive without extracting it and return its files
+func inspectZip(src string, limits Limits) ([]Entry, error) {
+	reader, err := zip.OpenReader(src)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return zipEntries(&reader.Reader, limits)
+}
+
+func zipEntries(reader *zip.Reader, limits Limits) ([]Entry, error) {
+	entries := []Entry{}
+	for _, zipFile := range reader.File {
+		if isIgnored(zipFile.Name) {
+			continue
+		}
+
+		name, err := cleanEntryName(zipFile.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		mode := zipFile.Mode()
+		switch {
+		case mode&fs.ModeSymlink != 0:
+			return nil, rejected(zipFile.Name, ErrSymlink)
+		case mode.IsDir():
+			continue
+		case !mode.IsRegular():
+			return nil, rejected(zipFile.Name, ErrUnsupportedEntry)
+		}
+
+		entries = append(entries, Entry{Name: name, Size: zipFile.UncompressedSize64, CompressedSize: zipFile.CompressedSize64})
+	}
+
+	return entries, checkEntries(entries, 0, limits)
+}
+
+// Extract a zip archive into dst after validating it
+func extractZip(src string, dst string, limits Limits, onProgress func(util.ExtractProgress)) error {
+	reader, err := zip.OpenReader(src)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	entries, err := zipEntries(&reader.Reader, limits)
+	if err != nil {
+		return err
+	}
+
+	progress := util.ExtractProgress{FilesTotal: len(entries)}
+	for _, entry := range entries {
+		progress.BytesTotal += entry.Size
+	}
+	onProgress(progress)
+
+	counter := &progressWriter{progress: &progress, onProgress: onProgress}
+
+	bar := util.SyntheticProgressBar(len(entries), "UNZIP")
+	bar.RenderBlank()
+
+	for _, zipFile := range reader.File {
+		if isIgnored(zipFile.Name) || zipFile.Mode().IsDir() {
+			continue
+		}
+
+		path, err := entryPath(dst, zipFile.Name)
+		if err != nil {
+			return err
+		}
+
+		srcReader, err := zipFile.Open()
+		if err != nil {
+			return err
+		}
+
+		err = extractFile(path, srcReader, zipFile.Name, zipFile.UncompressedSize64, counter, limits)
+		srcReader.Close()
+		if err != nil {
+			return err
+		}
+
+		bar.Add(1)
+		progress.FilesDone++
+		onProgress(progress)
+	}
+
+	fmt.Println()
+
+	return nil
+}
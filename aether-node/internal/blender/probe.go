@@ -0,0 +1,66 @@
+package blender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// How long "blender --version" may take; A cold start from a network share can be slow
+const probeTimeout = 30 * time.Second
+
+var versionRegex = regexp.MustCompile(`(?m)^Blender (\d+(?:\.\d+)+)`)
+var buildHashRegex = regexp.MustCompile(`(?m)build hash: ([0-9a-fA-F]+)`)
+
+// Run "blender --version" and return the version and build hash it reports
+func Probe(path string) (version string, buildHash string, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, path, "--version").Output()
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", "", fmt.Errorf("blender did not report its version within %s", probeTimeout)
+		}
+		return "", "", err
+	}
+
+	matches := versionRegex.FindSubmatch(output)
+	if matches == nil {
+		return "", "", fmt.Errorf("unexpected output of \"--version\": %s", strings.TrimSpace(string(output)))
+	}
+	version = string(matches[1])
+
+	if matches := buildHashRegex.FindSubmatch(output); matches != nil {
+		buildHash = string(matches[1])
+	}
+
+	return version, buildHash, nil
+}
+
+// Probe every installation. Installations that can not be run are kept, but marked as unavailable.
+func (registry *Registry) Detect() {
+	for i := range registry.installations {
+		installation := &registry.installations[i]
+
+		version, buildHash, err := Probe(installation.Path)
+		if err != nil {
+			installation.Available = false
+			installation.Error = err.Error()
+			logrus.Errorf("Blender \"%s\" (%s) is not usable: %s\n", installation.Name, installation.Path, err)
+			continue
+		}
+
+		installation.Available = true
+		installation.Error = ""
+		installation.Version = version
+		installation.BuildHash = buildHash
+		logrus.Infof("Detected Blender \"%s\": %s (build hash %s)\n", installation.Name, version, buildHash)
+	}
+}
@@ -0,0 +1,231 @@
+package blender
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Name of the installation configured by "blender" in the [Node] section
+const DefaultName = "default"
+
+type Installation struct {
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Version   string `json:"version"`
+	BuildHash string `json:"build_hash"`
+	Default   bool   `json:"default"`
+	Available bool   `json:"available"`
+	Error     string `json:"error,omitempty"`
+}
+
+// All Blender installations of the node, sorted by name. The default one comes first.
+type Registry struct {
+	installations []Installation
+}
+
+var ErrNoMatch = errors.New("no installed Blender matches")
+
+// Build the registry from the default executable and the named ones from [Blenders].
+// Names that look like a version ("3.6", "4.2.1") are taken as the version of the installation until Detect runs.
+// All installations count as available until then.
+func NewRegistry(defaultPath string, named map[string]string) *Registry {
+	registry := &Registry{
+		installations: []Installation{{Name: DefaultName, Path: defaultPath, Default: true, Available: true}},
+	}
+
+	names := make([]string, 0, len(named))
+	for name := range named {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		installation := Installation{Name: name, Path: named[name], Available: true}
+		if _, err := parseVersion(name); err == nil {
+			installation.Version = name
+		}
+		registry.installations = append(registry.installations, installation)
+	}
+
+	return registry
+}
+
+// Copy of all installations
+func (registry *Registry) Installations() []Installation {
+	return append([]Installation(nil), registry.installations...)
+}
+
+// The installation configured by "blender" in the [Node] section
+func (registry *Registry) Default() Installation {
+	return registry.installations[0]
+}
+
+// Whether at least one installation is available
+func (registry *Registry) AnyAvailable() bool {
+	for _, installation := range registry.installations {
+		if installation.Available {
+			return true
+		}
+	}
+	return false
+}
+
+// Whether some installations could not be run
+func (registry *Registry) Degraded() bool {
+	for _, installation := range registry.installations {
+		if !installation.Available {
+			return true
+		}
+	}
+	return false
+}
+
+func unavailable(installation Installation) error {
+	return fmt.Errorf("blender \"%s\" is not available: %s", installation.Name, installation.Error)
+}
+
+// Find the installation a render request asks for. The selector is either empty (the default installation),
+// the name of an installation or a comma separated list of version constraints like ">=3.6, <4.3" or "4.2".
+// A bare version matches all releases starting with it. Among several matching installations the newest one wins.
+func (registry *Registry) Resolve(selector string) (*Installation, error) {
+	selector = strings.TrimSpace(selector)
+	if selector == "" {
+		installation := registry.installations[0]
+		if !installation.Available {
+			return nil, unavailable(installation)
+		}
+		return &installation, nil
+	}
+
+	for _, installation := range registry.installations {
+		if installation.Name == selector {
+			if !installation.Available {
+				return nil, unavailable(installation)
+			}
+			return &installation, nil
+		}
+	}
+
+	// Anything else than a version constraint can only be a name
+	if !strings.ContainsAny(selector[:1], "<>=0123456789") {
+		return nil, fmt.Errorf("%w the name \"%s\"", ErrNoMatch, selector)
+	}
+
+	constraints, err := parseConstraints(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	var best *Installation = nil
+	var bestVersion []int
+	for i := range registry.installations {
+		version, err := parseVersion(registry.installations[i].Version)
+		if err != nil || !registry.installations[i].Available || !constraints.matches(version) {
+			continue
+		}
+		if best == nil || compareVersions(version, bestVersion) > 0 {
+			installation := registry.installations[i]
+			best = &installation
+			bestVersion = version
+		}
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("%w \"%s\"", ErrNoMatch, selector)
+	}
+	return best, nil
+}
+
+func parseVersion(s string) ([]int, error) {
+	if s == "" {
+		return nil, errors.New("empty version")
+	}
+
+	parts := strings.Split(s, ".")
+	version := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid version \"%s\"", s)
+		}
+		version[i] = n
+	}
+	return version, nil
+}
+
+// Compare two versions component-wise; Missing components count as zero
+func compareVersions(a []int, b []int) int {
+	for i := 0; i < max(len(a), len(b)); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			return x - y
+		}
+	}
+	return 0
+}
+
+type constraint struct {
+	operator string
+	version  []int
+}
+
+type constraints []constraint
+
+var operators = []string{">=", "<=", ">", "<", "="}
+
+func parseConstraints(selector string) (constraints, error) {
+	var result constraints
+	for _, part := range strings.Split(selector, ",") {
+		part = strings.TrimSpace(part)
+
+		operator := "="
+		for _, op := range operators {
+			if strings.HasPrefix(part, op) {
+				operator = op
+				part = strings.TrimSpace(strings.TrimPrefix(part, op))
+				break
+			}
+		}
+
+		version, err := parseVersion(part)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, constraint{operator: operator, version: version})
+	}
+	return result, nil
+}
+
+func (c constraint) matches(version []int) bool {
+	switch c.operator {
+	case ">=":
+		return compareVersions(version, c.version) >= 0
+	case "<=":
+		return compareVersions(version, c.version) <= 0
+	case ">":
+		return compareVersions(version, c.version) > 0
+	case "<":
+		return compareVersions(version, c.version) < 0
+	default:
+		// "4.2" matches 4.2, 4.2.0 and 4.2.1
+		return len(version) >= len(c.version) && slices.Equal(version[:len(c.version)], c.version)
+	}
+}
+
+func (cs constraints) matches(version []int) bool {
+	for _, c := range cs {
+		if !c.matches(version) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,11 +1,15 @@
 package config
 
 import (
+	"node/internal/archive"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
+	"github.com/dustin/go-humanize"
 	"github.com/sirupsen/logrus"
 )
 
@@ -16,6 +20,8 @@ type NodeConfig struct {
 		TempDirectory      string `toml:"temp_directory"`
 		ScenesDirectory    string `toml:"scenes_directory"`
 		SceneIndex         string `toml:"scene_index"`
+		QueueIndex         string `toml:"queue_index"`
+		JobIndex           string `toml:"job_index"`
 		WorkspaceDirectory string `toml:"workspace_directory"`
 		OutputDirectory    string `toml:"output_directory"`
 	} `toml:"Data"`
@@ -23,9 +29,37 @@ type NodeConfig struct {
 		Name    string `toml:"node_name"`
 		Port    uint16 `toml:"port"`
 		Blender string `toml:"blender"`
+		// Requeue jobs interrupted by a shutdown on startup instead of waiting for an explicit resume
+		ResumeInterrupted bool `toml:"resume_interrupted"`
 	} `toml:"Node"`
+	// Optional; Additional Blender installations by name, e.g. "4.2" = "/opt/blender-4.2/blender"
+	Blenders map[string]string `toml:"Blenders"`
+	// Optional; Extracted scenes are kept for later jobs until unused for max_age or until all of them exceed max_size
+	Workspaces struct {
+		MaxSize      string        `toml:"max_size"`
+		MaxAge       time.Duration `toml:"max_age"`
+		MaxSizeBytes uint64        `toml:"-"`
+	} `toml:"Workspaces"`
+	// Optional; A zero duration disables the respective check
+	Watchdog struct {
+		MaxJobDuration time.Duration `toml:"max_job_duration"`
+		StallTimeout   time.Duration `toml:"stall_timeout"`
+	} `toml:"Watchdog"`
+	// Optional; Limits for extracting uploaded scenes, unset values use the defaults below
+	Extraction struct {
+		MaxSize      string  `toml:"max_size"`
+		MaxFiles     int     `toml:"max_files"`
+		MaxRatio     float64 `toml:"max_ratio"`
+		MaxSizeBytes uint64  `toml:"-"`
+	} `toml:"Extraction"`
 }
 
+const (
+	defaultExtractionMaxSize  = "50 GB"
+	defaultExtractionMaxFiles = 100000
+	defaultExtractionMaxRatio = 1000
+)
+
 func validateConfig(cfg any) {
 	cfgValue := reflect.ValueOf(cfg)
 	cfgType := reflect.TypeOf(cfg)
@@ -47,15 +81,85 @@ func ParseNodeConfig() NodeConfig {
 	}
 
 	validateConfig(cfg.Node)
+
+	// The queue and job indexes are optional and default to files next to the scene index
+	if cfg.Data.QueueIndex == "" {
+		cfg.Data.QueueIndex = filepath.Join(filepath.Dir(cfg.Data.SceneIndex), "queue.json")
+	}
+	if cfg.Data.JobIndex == "" {
+		cfg.Data.JobIndex = filepath.Join(filepath.Dir(cfg.Data.SceneIndex), "jobs.json")
+	}
+
 	validateConfig(cfg.Data)
 
 	if !strings.HasSuffix(cfg.Data.SceneIndex, ".json") {
 		logrus.Fatalf("Scene index must be a JSON file, got \"%s\".", cfg.Data.SceneIndex)
 	}
 
+	if !strings.HasSuffix(cfg.Data.QueueIndex, ".json") {
+		logrus.Fatalf("Queue index must be a JSON file, got \"%s\".", cfg.Data.QueueIndex)
+	}
+
+	if !strings.HasSuffix(cfg.Data.JobIndex, ".json") {
+		logrus.Fatalf("Job index must be a JSON file, got \"%s\".", cfg.Data.JobIndex)
+	}
+
+	for name, path := range cfg.Blenders {
+		if name == "default" {
+			logrus.Fatal("The Blender installation name \"default\" is reserved for the \"blender\" setting")
+		}
+		if path == "" {
+			logrus.Fatalf("Config does not set a path for Blender \"%s\"", name)
+		}
+	}
+
+	if cfg.Workspaces.MaxSize != "" {
+		size, err := humanize.ParseBytes(cfg.Workspaces.MaxSize)
+		if err != nil {
+			logrus.Fatalf("Invalid workspace size \"%s\": %s", cfg.Workspaces.MaxSize, err)
+		}
+		cfg.Workspaces.MaxSizeBytes = size
+	}
+
+	if cfg.Workspaces.MaxAge < 0 {
+		logrus.Fatal("The workspace age must not be negative")
+	}
+
+	if cfg.Watchdog.MaxJobDuration < 0 || cfg.Watchdog.StallTimeout < 0 {
+		logrus.Fatal("Watchdog durations must not be negative")
+	}
+
+	if cfg.Extraction.MaxSize == "" {
+		cfg.Extraction.MaxSize = defaultExtractionMaxSize
+	}
+	size, err := humanize.ParseBytes(cfg.Extraction.MaxSize)
+	if err != nil {
+		logrus.Fatalf("Invalid extraction size \"%s\": %s", cfg.Extraction.MaxSize, err)
+	}
+	cfg.Extraction.MaxSizeBytes = size
+
+	if cfg.Extraction.MaxFiles == 0 {
+		cfg.Extraction.MaxFiles = defaultExtractionMaxFiles
+	}
+	if cfg.Extraction.MaxRatio == 0 {
+		cfg.Extraction.MaxRatio = defaultExtractionMaxRatio
+	}
+	if cfg.Extraction.MaxFiles < 0 || cfg.Extraction.MaxRatio < 0 {
+		logrus.Fatal("Extraction limits must not be negative")
+	}
+
 	return cfg
 }
 
+// Limits applied when extracting uploaded scenes
+func (cfg *NodeConfig) ExtractionLimits() archive.Limits {
+	return archive.Limits{
+		MaxSize:  cfg.Extraction.MaxSizeBytes,
+		MaxFiles: cfg.Extraction.MaxFiles,
+		MaxRatio: cfg.Extraction.MaxRatio,
+	}
+}
+
 func ensureFolder(path string) {
 	// TODO Handle other errors
 	_, err := os.Stat(path)
@@ -0,0 +1,69 @@
+package jobs
+
+import (
+	"node/internal/dto/render"
+	"node/internal/state"
+
+	"github.com/google/uuid"
+)
+
+type JobResponse struct {
+	ID             uuid.UUID            `json:"id"`
+	Request        render.RenderRequest `json:"request"`
+	Status         state.JobStatus      `json:"status"`
+	QueuedAt       int64                `json:"queued_at"`
+	StartedAt      int64                `json:"started_at"`
+	FinishedAt     int64                `json:"finished_at"`
+	ExitCode       *int                 `json:"exit_code"`
+	Signal         string               `json:"signal"`
+	Error          string               `json:"error"`
+	OutputTail     []string             `json:"output_tail"`
+	HasResult      bool                 `json:"has_result"`
+	HasLog         bool                 `json:"has_log"`
+	HasCrashReport bool                 `json:"has_crash_report"`
+	// Blender reported warnings or errors, so the frames are probably not as expected
+	Flagged      bool                `json:"flagged"`
+	WarningCount int                 `json:"warning_count"`
+	ErrorCount   int                 `json:"error_count"`
+	Issues       []state.OutputIssue `json:"issues"`
+}
+
+type JobIndexResponse struct {
+	Jobs []JobResponse `json:"jobs"`
+}
+
+func JobResponseFromJob(job *state.Job) JobResponse {
+	return JobResponse{
+		ID:             job.ID,
+		Request:        job.Request,
+		Status:         job.Status,
+		QueuedAt:       job.QueuedAt,
+		StartedAt:      job.StartedAt,
+		FinishedAt:     job.FinishedAt,
+		ExitCode:       job.ExitCode,
+		Signal:         job.Signal,
+		Error:          job.Error,
+		OutputTail:     job.OutputTail,
+		HasResult:      job.OutputPath != "",
+		HasLog:         job.LogPath != "",
+		HasCrashReport: job.CrashReport != "",
+		Flagged:        job.WarningCount+job.ErrorCount > 0,
+		WarningCount:   job.WarningCount,
+		ErrorCount:     job.ErrorCount,
+		Issues:         job.Issues,
+	}
+}
+
+func JobIndexResponseFromJobs(jobs []state.Job) JobIndexResponse {
+	var responses = []JobResponse{}
+	for i := range jobs {
+		responses = append(responses, JobResponseFromJob(&jobs[i]))
+	}
+	return JobIndexResponse{Jobs: responses}
+}
+
+type FramesResponse struct {
+	JobID  uuid.UUID          `json:"job_id"`
+	Status state.JobStatus    `json:"status"`
+	Frames []state.SavedFrame `json:"frames"`
+}
@@ -1,28 +1,55 @@
 package progress
 
 import (
+	"math"
+	"node/internal/dto/jobs"
 	"node/internal/dto/render"
 	"node/internal/state"
+	"node/internal/util"
+
+	"github.com/google/uuid"
 )
 
 type RenderProgress struct {
 	CurrentFrame  int     `json:"current_frame"`
 	FramePercent  float64 `json:"frame_percent"`
 	FrameCount    int     `json:"frame_count"`
+	FramesDone    int     `json:"frames_done"`
+	TotalPercent  float64 `json:"total_percent"`
 	TimeElapsed   float64 `json:"time_elapsed"`
 	TimeRemaining float64 `json:"time_remaining"`
+	RenderTime    float64 `json:"render_time"`
+	Sample        int     `json:"sample"`
+	SampleCount   int     `json:"sample_count"`
+	Tile          int     `json:"tile"`
+	TileCount     int     `json:"tile_count"`
+	PeakMemory    float64 `json:"peak_memory"`
 }
 
 type StatusResponse struct {
-	IsRendering bool                  `json:"is_rendering"`
-	Request     *render.RenderRequest `json:"request"`
-	Progress    *RenderProgress       `json:"progress"`
+	State        state.Phase             `json:"state"`
+	StateSince   int64                   `json:"state_since"`
+	Transitions  []state.PhaseTransition `json:"transitions"`
+	IsRendering  bool                    `json:"is_rendering"`
+	IsPaused     bool                    `json:"is_paused"`
+	JobID        *uuid.UUID              `json:"job_id"`
+	Request      *render.RenderRequest   `json:"request"`
+	Preparation  *util.ExtractProgress   `json:"preparation"`
+	Progress     *RenderProgress         `json:"progress"`
+	WarningCount int                     `json:"warning_count"`
+	ErrorCount   int                     `json:"error_count"`
+	Issues       []state.OutputIssue     `json:"issues"`
+	QueueLength  int                     `json:"queue_length"`
+	LastJob      *jobs.JobResponse       `json:"last_job"`
 }
 
 func EmptyStatusResponse() StatusResponse {
 	return StatusResponse{
 		IsRendering: false,
+		IsPaused:    false,
+		JobID:       nil,
 		Request:     nil,
+		Preparation: nil,
 		Progress:    nil,
 	}
 }
@@ -33,14 +60,42 @@ func StatusResponseFromRenderState(state *state.RendererState) StatusResponse {
 	}
 
 	return StatusResponse{
-		IsRendering: true,
-		Request:     &state.Request,
+		IsRendering:  true,
+		IsPaused:     state.Paused,
+		JobID:        &state.JobID,
+		Request:      &state.Request,
+		Preparation:  &state.Preparation,
+		WarningCount: state.WarningCount,
+		ErrorCount:   state.ErrorCount,
+		Issues:       state.Issues,
 		Progress: &RenderProgress{
 			CurrentFrame:  state.CurrentFrame,
 			FramePercent:  state.FramePercent,
-			FrameCount:    int(*state.Request.FrameEnd-*state.Request.FrameStart) + 1,
+			FrameCount:    state.FrameCount,
+			FramesDone:    state.FramesDone,
+			TotalPercent:  totalPercent(state),
 			TimeElapsed:   state.TimeElapsed,
 			TimeRemaining: state.TimeRemaining,
+			RenderTime:    state.ActiveTime().Seconds(),
+			Sample:        state.Sample,
+			SampleCount:   state.SampleCount,
+			Tile:          state.Tile,
+			TileCount:     state.TileCount,
+			PeakMemory:    state.PeakMemory,
 		},
 	}
 }
+
+// Progress of the whole job, counting the current frame partially
+func totalPercent(state *state.RendererState) float64 {
+	if state.FrameCount == 0 {
+		return 0
+	}
+
+	done := float64(state.FramesDone)
+	if state.FramesDone < state.FrameCount {
+		done += state.FramePercent / 100
+	}
+
+	return math.Min(done/float64(state.FrameCount)*100, 100)
+}
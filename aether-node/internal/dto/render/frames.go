@@ -0,0 +1,206 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Frame limits of Blender (MINAFRAME and MAXFRAME)
+const (
+	MinFrame = -1048574
+	MaxFrame = 1048574
+)
+
+// Frame lists and ranges are expanded when the job starts, so a request may not select more frames than Blender has
+const MaxFrameCount = MaxFrame - MinFrame + 1
+
+type FrameRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+	Step  int `json:"step"`
+}
+
+// Step of the range; A missing step renders every frame
+func (r FrameRange) StepOrDefault() int {
+	if r.Step <= 0 {
+		return 1
+	}
+	return r.Step
+}
+
+func (r FrameRange) validate(name string) error {
+	if r.Start < MinFrame || r.End > MaxFrame {
+		return fmt.Errorf("%s must lie within %d and %d", name, MinFrame, MaxFrame)
+	}
+	if r.Start > r.End {
+		return fmt.Errorf("%s must not start after it ends (%d to %d)", name, r.Start, r.End)
+	}
+	if r.Step < 0 {
+		return fmt.Errorf("%s must not have a negative step", name)
+	}
+	return nil
+}
+
+// Number of frames in the range, which has to be valid
+func (r FrameRange) count() int {
+	return (r.End-r.Start)/r.StepOrDefault() + 1
+}
+
+func (r FrameRange) String() string {
+	s := strconv.Itoa(r.Start) + ".." + strconv.Itoa(r.End)
+	if step := r.StepOrDefault(); step != 1 {
+		s += " every " + strconv.Itoa(step)
+	}
+	return s
+}
+
+// Check that the request selects a valid, non-empty set of frames
+func (req *RenderRequest) ValidateFrames() error {
+	hasRange := req.FrameStart != nil || req.FrameEnd != nil
+	styles := 0
+	for _, used := range []bool{hasRange, req.Frames != nil, req.FrameRanges != nil} {
+		if used {
+			styles++
+		}
+	}
+
+	if styles == 0 {
+		return errors.New("expected either \"frame_start\" and \"frame_end\", \"frames\" or \"frame_ranges\"")
+	}
+	if styles > 1 {
+		return errors.New("only one of \"frame_start\"/\"frame_end\", \"frames\" or \"frame_ranges\" may be used")
+	}
+
+	if hasRange {
+		if req.FrameStart == nil {
+			return errors.New("expected required field \"frame_start\"")
+		}
+		if req.FrameEnd == nil {
+			return errors.New("expected required field \"frame_end\"")
+		}
+		if req.FrameStep != nil && *req.FrameStep < 1 {
+			return errors.New("\"frame_step\" must be at least 1")
+		}
+		return req.Ranges()[0].validate("the frame range")
+	}
+
+	if req.FrameStep != nil {
+		return errors.New("\"frame_step\" can only be used together with \"frame_start\" and \"frame_end\"")
+	}
+
+	if req.Frames != nil {
+		if len(req.Frames) == 0 {
+			return errors.New("\"frames\" must not be empty")
+		}
+		for _, frame := range req.Frames {
+			if frame < MinFrame || frame > MaxFrame {
+				return fmt.Errorf("frame %d must lie within %d and %d", frame, MinFrame, MaxFrame)
+			}
+		}
+		if len(req.Frames) > MaxFrameCount {
+			return fmt.Errorf("\"frames\" must not list more than %d frames", MaxFrameCount)
+		}
+		return nil
+	}
+
+	if len(req.FrameRanges) == 0 {
+		return errors.New("\"frame_ranges\" must not be empty")
+	}
+	// Overlapping ranges are counted twice, since they are expanded before duplicates are removed
+	selected := 0
+	for i, r := range req.FrameRanges {
+		if err := r.validate("frame range #" + strconv.Itoa(i+1)); err != nil {
+			return err
+		}
+		selected += r.count()
+		if selected > MaxFrameCount {
+			return fmt.Errorf("\"frame_ranges\" must not select more than %d frames in total", MaxFrameCount)
+		}
+	}
+
+	return nil
+}
+
+// Frame ranges selected by the request. Explicit frame lists are not included.
+func (req *RenderRequest) Ranges() []FrameRange {
+	if req.FrameStart != nil && req.FrameEnd != nil {
+		r := FrameRange{Start: *req.FrameStart, End: *req.FrameEnd}
+		if req.FrameStep != nil {
+			r.Step = *req.FrameStep
+		}
+		return []FrameRange{r}
+	}
+	return req.FrameRanges
+}
+
+// Whether Blender's own animation range (-s, -e, -j) can render the request. It can not go below frame 0.
+func (req *RenderRequest) IsSimpleRange() bool {
+	return req.FrameStart != nil && req.FrameEnd != nil && *req.FrameStart >= 0
+}
+
+// Sorted list of all distinct frames selected by the request
+func (req *RenderRequest) FrameList() []int {
+	if req.IsSimpleRange() {
+		r := req.Ranges()[0]
+		frames := make([]int, 0, r.count())
+		for frame := r.Start; frame <= r.End; frame += r.StepOrDefault() {
+			frames = append(frames, frame)
+		}
+		return frames
+	}
+
+	frames := append([]int{}, req.Frames...)
+	for _, r := range req.Ranges() {
+		for frame := r.Start; frame <= r.End; frame += r.StepOrDefault() {
+			frames = append(frames, frame)
+		}
+	}
+
+	slices.Sort(frames)
+	return slices.Compact(frames)
+}
+
+// Ranges covering a sorted list of distinct frames. Consecutive frames and runs of three or more evenly spaced frames
+// are merged, every other frame becomes a range of its own.
+func CompactFrames(frames []int) []FrameRange {
+	var ranges []FrameRange
+	for i := 0; i < len(frames); {
+		end, step := i, 1
+		if i+1 < len(frames) {
+			step = frames[i+1] - frames[i]
+			for end+1 < len(frames) && frames[end+1]-frames[end] == step {
+				end++
+			}
+			if step != 1 && end-i < 2 {
+				end, step = i, 1
+			}
+		}
+
+		ranges = append(ranges, FrameRange{Start: frames[i], End: frames[end], Step: step})
+		i = end + 1
+	}
+	return ranges
+}
+
+// Number of distinct frames selected by the request
+func (req *RenderRequest) FrameCount() int {
+	if req.IsSimpleRange() {
+		return req.Ranges()[0].count()
+	}
+	return len(req.FrameList())
+}
+
+// Human-readable description of the selected frames, used for logging
+func (req *RenderRequest) DescribeFrames() string {
+	var parts []string
+	for _, r := range req.Ranges() {
+		parts = append(parts, r.String())
+	}
+	if len(req.Frames) > 0 {
+		parts = append(parts, strconv.Itoa(len(req.Frames))+" listed frames")
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,65 @@
+package render
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCompactFrames(t *testing.T) {
+	tests := []struct {
+		name   string
+		frames []int
+		ranges []FrameRange
+	}{
+		{
+			name:   "empty",
+			frames: nil,
+			ranges: nil,
+		},
+		{
+			name:   "single frame",
+			frames: []int{7},
+			ranges: []FrameRange{{Start: 7, End: 7, Step: 1}},
+		},
+		{
+			name:   "consecutive frames",
+			frames: []int{1, 2, 3, 4},
+			ranges: []FrameRange{{Start: 1, End: 4, Step: 1}},
+		},
+		{
+			name:   "two consecutive frames",
+			frames: []int{-1, 0},
+			ranges: []FrameRange{{Start: -1, End: 0, Step: 1}},
+		},
+		{
+			name:   "evenly spaced frames",
+			frames: []int{10, 20, 30, 40},
+			ranges: []FrameRange{{Start: 10, End: 40, Step: 10}},
+		},
+		{
+			name:   "two spaced frames stay apart",
+			frames: []int{1, 100},
+			ranges: []FrameRange{{Start: 1, End: 1, Step: 1}, {Start: 100, End: 100, Step: 1}},
+		},
+		{
+			name:   "mixed runs",
+			frames: []int{1, 2, 3, 7, 20, 22, 24, 25},
+			ranges: []FrameRange{{Start: 1, End: 3, Step: 1}, {Start: 7, End: 7, Step: 1}, {Start: 20, End: 24, Step: 2}, {Start: 25, End: 25, Step: 1}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ranges := CompactFrames(test.frames)
+			if !slices.Equal(ranges, test.ranges) {
+				t.Fatalf("expected %v, got %v", test.ranges, ranges)
+			}
+
+			// The ranges have to select exactly the given frames again
+			req := RenderRequest{FrameRanges: ranges}
+			if frames := req.FrameList(); !slices.Equal(frames, test.frames) && len(test.frames) > 0 {
+				t.Fatalf("ranges select %v instead of %v", frames, test.frames)
+			}
+		})
+	}
+}
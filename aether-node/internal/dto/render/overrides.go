@@ -0,0 +1,94 @@
+package render
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+type OverrideKind int
+
+const (
+	OverrideInt OverrideKind = iota
+	OverrideFloat
+	OverrideBool
+)
+
+func (kind OverrideKind) String() string {
+	switch kind {
+	case OverrideInt:
+		return "an integer"
+	case OverrideFloat:
+		return "a number"
+	default:
+		return "a boolean"
+	}
+}
+
+type OverrideSpec struct {
+	Kind OverrideKind
+	Min  float64
+	Max  float64
+}
+
+// Scene settings that may be overridden per request, relative to `bpy.context.scene`
+var OverrideSpecs = map[string]OverrideSpec{
+	"render.resolution_x":          {Kind: OverrideInt, Min: 4, Max: 65536},
+	"render.resolution_y":          {Kind: OverrideInt, Min: 4, Max: 65536},
+	"render.resolution_percentage": {Kind: OverrideInt, Min: 1, Max: 32767},
+	"render.film_transparent":      {Kind: OverrideBool},
+	"render.use_motion_blur":       {Kind: OverrideBool},
+	"render.use_simplify":          {Kind: OverrideBool},
+	"cycles.samples":               {Kind: OverrideInt, Min: 1, Max: 16777216},
+	"cycles.seed":                  {Kind: OverrideInt, Min: 0, Max: math.MaxInt32},
+	"cycles.use_animated_seed":     {Kind: OverrideBool},
+	"cycles.use_denoising":         {Kind: OverrideBool},
+	"cycles.use_adaptive_sampling": {Kind: OverrideBool},
+	"cycles.adaptive_threshold":    {Kind: OverrideFloat, Min: 0, Max: 1},
+	"cycles.max_bounces":           {Kind: OverrideInt, Min: 0, Max: 1024},
+	"cycles.time_limit":            {Kind: OverrideFloat, Min: 0, Max: math.MaxFloat32},
+	"eevee.taa_render_samples":     {Kind: OverrideInt, Min: 1, Max: 65536},
+}
+
+// Check all overrides against the allowlist and their expected value types
+func ValidateOverrides(overrides map[string]interface{}) error {
+	for key, value := range overrides {
+		spec, ok := OverrideSpecs[key]
+		if !ok {
+			return fmt.Errorf("unknown override \"%s\", expected one of: %s", key, strings.Join(OverrideKeys(), ", "))
+		}
+
+		if spec.Kind == OverrideBool {
+			if _, ok := value.(bool); !ok {
+				return fmt.Errorf("override \"%s\" must be a boolean", key)
+			}
+			continue
+		}
+
+		number, ok := value.(float64)
+		if !ok || math.IsNaN(number) || math.IsInf(number, 0) {
+			return fmt.Errorf("override \"%s\" must be %s", key, spec.Kind)
+		}
+
+		if spec.Kind == OverrideInt && number != math.Trunc(number) {
+			return fmt.Errorf("override \"%s\" must be an integer", key)
+		}
+
+		if number < spec.Min || number > spec.Max {
+			return fmt.Errorf("override \"%s\" must be between %g and %g", key, spec.Min, spec.Max)
+		}
+	}
+
+	return nil
+}
+
+// Sorted list of all keys that may be overridden
+func OverrideKeys() []string {
+	keys := make([]string, 0, len(OverrideSpecs))
+	for key := range OverrideSpecs {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
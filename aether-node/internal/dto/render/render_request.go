@@ -1,9 +1,37 @@
 package render
 
-import "github.com/google/uuid"
+import (
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// Image formats that may be requested; Passed to Blender's "-F" flag
+var OutputFormats = []string{"PNG", "JPEG", "OPEN_EXR", "OPEN_EXR_MULTILAYER", "TIFF"}
+
+// Render engines that may be requested; Passed to Blender's "-E" flag
+var Engines = []string{"CYCLES", "BLENDER_EEVEE", "BLENDER_EEVEE_NEXT", "BLENDER_WORKBENCH"}
 
 type RenderRequest struct {
-	ID         *uuid.UUID `json:"id"`
-	FrameStart *uint16    `json:"frame_start"`
-	FrameEnd   *uint16    `json:"frame_end"`
+	ID           *uuid.UUID             `json:"id"`
+	FrameStart   *int                   `json:"frame_start"`
+	FrameEnd     *int                   `json:"frame_end"`
+	FrameStep    *int                   `json:"frame_step"`
+	Frames       []int                  `json:"frames"`
+	FrameRanges  []FrameRange           `json:"frame_ranges"`
+	OutputFormat *string                `json:"output_format"`
+	Scene        *string                `json:"scene"`
+	Engine       *string                `json:"engine"`
+	Overrides    map[string]interface{} `json:"overrides"`
+	MaxDuration  *int                   `json:"max_duration"`
+	Blender      *string                `json:"blender"`
+	BlendFile    *string                `json:"blend_file"`
+}
+
+func IsValidOutputFormat(format string) bool {
+	return slices.Contains(OutputFormats, format)
+}
+
+func IsValidEngine(engine string) bool {
+	return slices.Contains(Engines, engine)
 }
@@ -10,6 +10,8 @@ type SceneResponse struct {
 	CreatedAt    int64     `json:"created_at"`
 	ID           uuid.UUID `json:"id"`
 	OriginalName string    `json:"original_name"`
+	BlendFiles   []string  `json:"blend_files"`
+	MainFile     string    `json:"main_file"`
 }
 
 type SceneIndexResponse struct {
@@ -18,11 +20,13 @@ type SceneIndexResponse struct {
 
 func SceneIndexResponseFromIndex(index *persistence.SceneIndex) SceneIndexResponse {
 	var scenes []SceneResponse
-	for _, v := range index.Scenes {
+	for _, v := range index.AllScenes() {
 		scenes = append(scenes, SceneResponse{
 			CreatedAt:    v.CreatedAt,
 			ID:           v.ID,
 			OriginalName: v.OriginalName,
+			BlendFiles:   v.BlendFiles,
+			MainFile:     v.MainFile,
 		})
 	}
 	return SceneIndexResponse{Scenes: scenes}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"node/internal/api"
 	"node/internal/banner"
+	"node/internal/blender"
 	"node/internal/config"
 	"node/internal/state"
 	"node/internal/util"
@@ -28,7 +29,7 @@ func establishPlatform() state.Platform {
 		return state.Unix
 	}
 
-	logrus.Fatal("Could not determine platform: %s\n", system)
+	logrus.Fatalf("Could not determine platform: %s\n", system)
 	return state.Unix // Doesn't really matter
 }
 
@@ -47,19 +48,31 @@ func InitializeNode() {
 		Port:     port,
 		Color:    util.RandomNodeColor(),
 		Platform: establishPlatform(),
+		Blenders: blender.NewRegistry(cfg.Node.Blender, cfg.Blenders),
 		State: state.State{
-			UploadLock:    sync.Mutex{},
-			RenderLock:    sync.Mutex{},
-			RendererState: nil,
+			UploadLock: sync.Mutex{},
 		}}
 
+	n.State.SetPhase(state.PhaseIdle)
+
 	if n.Platform == state.Windows {
 		logrus.Infof("Aether node is running on Windows")
 	} else {
 		logrus.Infof("Aether node is running on Unix")
 	}
 
-	logrus.Infof("Using blender path: %s\n", cfg.Node.Blender)
+	for _, installation := range n.Blenders.Installations() {
+		logrus.Infof("Using blender \"%s\": %s\n", installation.Name, installation.Path)
+	}
+
+	// Jobs selecting a broken installation are rejected; Without any working one the node is useless
+	n.Blenders.Detect()
+	if !n.Blenders.AnyAvailable() {
+		logrus.Fatal("None of the configured Blender installations can be run")
+	}
+	if n.Blenders.Degraded() {
+		logrus.Warnf("Starting in a degraded state, some Blender installations are not available\n")
+	}
 
 	// Make sure all required directories exist
 	cfg.EnsureFolders()
@@ -0,0 +1,141 @@
+package persistence
+
+import (
+	"encoding/json"
+	"node/internal/config"
+	"node/internal/state"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type JobIndex struct {
+	CreatedAt int64       `json:"created_at"`
+	Jobs      []state.Job `json:"jobs"`
+
+	mutex sync.Mutex
+}
+
+// Insert a new job or replace the stored record of an existing one
+func (index *JobIndex) UpdateJob(job state.Job, cfg *config.NodeConfig) {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+
+	for i := range index.Jobs {
+		if index.Jobs[i].ID == job.ID {
+			index.Jobs[i] = job
+			StoreJobIndex(cfg, index)
+			return
+		}
+	}
+
+	index.Jobs = append(index.Jobs, job)
+	StoreJobIndex(cfg, index)
+}
+
+func (index *JobIndex) FindJobById(id uuid.UUID) *state.Job {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+
+	for i := range index.Jobs {
+		job := index.Jobs[i]
+		if job.ID == id {
+			return &job
+		}
+	}
+
+	return nil
+}
+
+// Find the most recently finished job of a scene that produced a result
+func (index *JobIndex) FindLatestResult(sceneId uuid.UUID) *state.Job {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+
+	var latest *state.Job = nil
+	for i := range index.Jobs {
+		job := index.Jobs[i]
+		if job.Request.ID == nil || *job.Request.ID != sceneId || job.OutputPath == "" {
+			continue
+		}
+		if latest == nil || job.FinishedAt > latest.FinishedAt {
+			latest = &job
+		}
+	}
+
+	return latest
+}
+
+// Return a copy of all jobs, oldest first
+func (index *JobIndex) AllJobs() []state.Job {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+
+	jobs := make([]state.Job, len(index.Jobs))
+	copy(jobs, index.Jobs)
+	return jobs
+}
+
+func LoadJobIndex(cfg *config.NodeConfig) *JobIndex {
+	var index = &JobIndex{
+		Jobs:      []state.Job{},
+		CreatedAt: time.Now().UnixNano(),
+	}
+
+	file, err := os.ReadFile(cfg.Data.JobIndex)
+	if os.IsNotExist(err) {
+		logrus.Infof("Created job index file \"%s\".\n", cfg.Data.JobIndex)
+		StoreJobIndex(cfg, index)
+		return index
+	}
+	if err != nil {
+		logrus.Errorf("Could not read job index: %s\n", err)
+		return index
+	}
+
+	err = json.Unmarshal(file, index)
+	if err != nil {
+		logrus.Errorf("Could not load job index: %s\n", err)
+		return index
+	}
+
+	// Jobs that were running when the node went down have to be resumed explicitly (or by "resume_interrupted")
+	interrupted := 0
+	for i := range index.Jobs {
+		if index.Jobs[i].Status == state.JobRunning {
+			index.Jobs[i].Status = state.JobInterrupted
+			index.Jobs[i].Error = "The node was stopped while the job was running"
+			interrupted++
+		}
+	}
+
+	if interrupted > 0 {
+		logrus.Warnf("Marked %d jobs as interrupted.\n", interrupted)
+		StoreJobIndex(cfg, index)
+	}
+
+	logrus.Infof("Loaded %d jobs.\n", len(index.Jobs))
+
+	return index
+}
+
+// Write the job index to disk. The caller must hold the index mutex (or own the index exclusively).
+func StoreJobIndex(cfg *config.NodeConfig, index *JobIndex) {
+	b, err := json.MarshalIndent(index, "", "\t")
+	if err != nil {
+		logrus.Errorf("Could not marshal job index: %s", err)
+		return
+	}
+
+	err = os.WriteFile(cfg.Data.JobIndex, b, os.ModePerm)
+	if err != nil {
+		logrus.Errorf("Could not write job index: %s", err)
+		return
+	}
+
+	logrus.Debugf("Written job index (%s)\n", humanize.Bytes(uint64(len(b))))
+}
@@ -0,0 +1,147 @@
+package persistence
+
+import (
+	"encoding/json"
+	"node/internal/config"
+	"node/internal/state"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type JobQueue struct {
+	CreatedAt int64       `json:"created_at"`
+	Jobs      []state.Job `json:"jobs"`
+
+	mutex  sync.Mutex
+	signal chan struct{}
+}
+
+// Append a job to the end of the queue and return its position (starting at 1)
+func (queue *JobQueue) Enqueue(job state.Job, cfg *config.NodeConfig) int {
+	queue.mutex.Lock()
+	queue.Jobs = append(queue.Jobs, job)
+	position := len(queue.Jobs)
+	StoreQueue(cfg, queue)
+	queue.mutex.Unlock()
+
+	// Wake up the worker if it is waiting for work
+	select {
+	case queue.signal <- struct{}{}:
+	default:
+	}
+
+	return position
+}
+
+// Put a job in front of all waiting jobs
+func (queue *JobQueue) EnqueueFront(job state.Job, cfg *config.NodeConfig) int {
+	queue.mutex.Lock()
+	queue.Jobs = append([]state.Job{job}, queue.Jobs...)
+	StoreQueue(cfg, queue)
+	queue.mutex.Unlock()
+
+	select {
+	case queue.signal <- struct{}{}:
+	default:
+	}
+
+	return 1
+}
+
+// Remove and return the oldest job. Blocks until a job is available.
+// The job is passed to start before its removal is saved, so it is always either waiting or started.
+func (queue *JobQueue) Next(cfg *config.NodeConfig, start func(job *state.Job)) state.Job {
+	for {
+		queue.mutex.Lock()
+		if len(queue.Jobs) > 0 {
+			job := queue.Jobs[0]
+			start(&job)
+			queue.Jobs = queue.Jobs[1:]
+			StoreQueue(cfg, queue)
+			queue.mutex.Unlock()
+			return job
+		}
+		queue.mutex.Unlock()
+
+		<-queue.signal
+	}
+}
+
+// Remove a waiting job from the queue. Returns false if no job with this ID is waiting.
+func (queue *JobQueue) Remove(id uuid.UUID, cfg *config.NodeConfig) bool {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	for i := range queue.Jobs {
+		if queue.Jobs[i].ID == id {
+			queue.Jobs = append(queue.Jobs[:i], queue.Jobs[i+1:]...)
+			StoreQueue(cfg, queue)
+			return true
+		}
+	}
+
+	return false
+}
+
+// Return a copy of all jobs that are currently waiting
+func (queue *JobQueue) Pending() []state.Job {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	jobs := make([]state.Job, len(queue.Jobs))
+	copy(jobs, queue.Jobs)
+	return jobs
+}
+
+func LoadJobQueue(cfg *config.NodeConfig) *JobQueue {
+	var queue = &JobQueue{
+		Jobs:      []state.Job{},
+		CreatedAt: time.Now().UnixNano(),
+		signal:    make(chan struct{}, 1),
+	}
+
+	file, err := os.ReadFile(cfg.Data.QueueIndex)
+	if os.IsNotExist(err) {
+		logrus.Infof("Created queue index file \"%s\".\n", cfg.Data.QueueIndex)
+		StoreQueue(cfg, queue)
+		return queue
+	}
+	if err != nil {
+		logrus.Errorf("Could not read queue index: %s\n", err)
+		return queue
+	}
+
+	err = json.Unmarshal(file, queue)
+	if err != nil {
+		logrus.Errorf("Could not load queue index: %s\n", err)
+		return queue
+	}
+
+	if jobCount := len(queue.Jobs); jobCount > 0 {
+		logrus.Infof("Restored %d queued render jobs.\n", jobCount)
+	}
+
+	return queue
+}
+
+// Write the queue to disk. The caller must hold the queue mutex (or own the queue exclusively).
+func StoreQueue(cfg *config.NodeConfig, queue *JobQueue) {
+	b, err := json.MarshalIndent(queue, "", "\t")
+	if err != nil {
+		logrus.Errorf("Could not marshal job queue: %s", err)
+		return
+	}
+
+	err = os.WriteFile(cfg.Data.QueueIndex, b, os.ModePerm)
+	if err != nil {
+		logrus.Errorf("Could not write queue index: %s", err)
+		return
+	}
+
+	logrus.Debugf("Written queue index (%s)\n", humanize.Bytes(uint64(len(b))))
+}
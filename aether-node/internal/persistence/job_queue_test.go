@@ -0,0 +1,43 @@
+package persistence
+
+import (
+	"node/internal/config"
+	"node/internal/state"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// A job taken off the queue must still be stored as waiting until the worker has started it
+func TestJobQueueNextStartsBeforeRemoval(t *testing.T) {
+	logrus.SetLevel(logrus.WarnLevel)
+
+	var cfg config.NodeConfig
+	cfg.Data.QueueIndex = filepath.Join(t.TempDir(), "queue.json")
+
+	queue := LoadJobQueue(&cfg)
+	first, second := state.Job{ID: uuid.New()}, state.Job{ID: uuid.New()}
+	queue.Enqueue(first, &cfg)
+	queue.Enqueue(second, &cfg)
+
+	started := false
+	job := queue.Next(&cfg, func(job *state.Job) {
+		started = true
+		if stored := LoadJobQueue(&cfg); len(stored.Jobs) != 2 {
+			t.Errorf("job was removed from the stored queue before it started, %d jobs left", len(stored.Jobs))
+		}
+		job.Status = state.JobRunning
+	})
+
+	if !started {
+		t.Fatal("start was not called")
+	}
+	if job.ID != first.ID || job.Status != state.JobRunning {
+		t.Errorf("got job %s (%s), want %s (%s)", job.ID, job.Status, first.ID, state.JobRunning)
+	}
+	if stored := LoadJobQueue(&cfg); len(stored.Jobs) != 1 || stored.Jobs[0].ID != second.ID {
+		t.Errorf("stored queue should only hold the second job, got %v", stored.Jobs)
+	}
+}
@@ -2,10 +2,13 @@ package persistence
 
 import (
 	"encoding/json"
+	"node/internal/archive"
 	"node/internal/checksum"
 	"node/internal/config"
 	"node/internal/state"
 	"os"
+	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/dustin/go-humanize"
@@ -16,14 +19,32 @@ import (
 type SceneIndex struct {
 	CreatedAt int64                 `json:"created_at"`
 	Scenes    []state.SceneMetadata `json:"scenes"`
+
+	mutex sync.RWMutex
 }
 
 func (store *SceneIndex) AddScene(scene state.SceneMetadata, cfg *config.NodeConfig) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
 	store.Scenes = append(store.Scenes, scene)
 	StoreIndex(cfg, store)
 }
 
+// Return a copy of all stored scenes
+func (store *SceneIndex) AllScenes() []state.SceneMetadata {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	scenes := make([]state.SceneMetadata, len(store.Scenes))
+	copy(scenes, store.Scenes)
+	return scenes
+}
+
 func (store *SceneIndex) FindSceneByChecksum(checksum checksum.Checksum) *state.SceneMetadata {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
 	for i := range store.Scenes {
 		scene := store.Scenes[i]
 		if scene.Checksum.IsSame(&checksum) {
@@ -35,6 +56,9 @@ func (store *SceneIndex) FindSceneByChecksum(checksum checksum.Checksum) *state.
 }
 
 func (store *SceneIndex) FindSceneById(id uuid.UUID) *state.SceneMetadata {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
 	for i := range store.Scenes {
 		scene := store.Scenes[i]
 		if scene.ID == id {
@@ -65,14 +89,14 @@ func (store *SceneIndex) EnsureSceneIndex(cfg *config.NodeConfig) bool {
 	return true
 }
 
-func LoadStoredScenes(cfg *config.NodeConfig) SceneIndex {
-	var store = SceneIndex{
+func LoadStoredScenes(cfg *config.NodeConfig) *SceneIndex {
+	var store = &SceneIndex{
 		Scenes:    []state.SceneMetadata{},
 		CreatedAt: time.Now().UnixNano(),
 	}
 
 	// If the file was just created, the index is going to be empty. No need to proceed
-	if (&store).EnsureSceneIndex(cfg) {
+	if store.EnsureSceneIndex(cfg) {
 		return store
 	}
 
@@ -81,12 +105,33 @@ func LoadStoredScenes(cfg *config.NodeConfig) SceneIndex {
 		return store
 	}
 
-	err = json.Unmarshal(file, &store)
+	err = json.Unmarshal(file, store)
 	if err != nil {
 		logrus.Errorf("Could not load scene index: %s\n", err)
 		return store
 	}
 
+	// Scenes uploaded by older versions do not list their *.blend files yet
+	listed := 0
+	for i := range store.Scenes {
+		if store.Scenes[i].BlendFiles != nil {
+			continue
+		}
+
+		// Limits are not applied here; They are enforced once the scene is extracted
+		_, entries, err := archive.Inspect(filepath.Join(cfg.Data.ScenesDirectory, store.Scenes[i].Filename), archive.Limits{})
+		if err != nil {
+			logrus.Errorf("Could not list *.blend files of scene %s: %s\n", store.Scenes[i].ID, err)
+			continue
+		}
+		store.Scenes[i].BlendFiles = archive.BlendFiles(entries)
+		listed++
+	}
+
+	if listed > 0 {
+		StoreIndex(cfg, store)
+	}
+
 	sceneCount := len(store.Scenes)
 
 	if sceneCount > 0 {
@@ -98,6 +143,7 @@ func LoadStoredScenes(cfg *config.NodeConfig) SceneIndex {
 	return store
 }
 
+// Write the scene index to disk. The caller must hold the index mutex (or own the index exclusively).
 func StoreIndex(cfg *config.NodeConfig, store *SceneIndex) {
 	b, err := json.MarshalIndent(store, "", "\t")
 	if err != nil {
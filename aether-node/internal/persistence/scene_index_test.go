@@ -0,0 +1,61 @@
+package persistence
+
+import (
+	"node/internal/config"
+	"node/internal/state"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Uploads add scenes while render requests look them up; Run with -race
+func TestSceneIndexConcurrentAccess(t *testing.T) {
+	logrus.SetLevel(logrus.WarnLevel)
+
+	var cfg config.NodeConfig
+	cfg.Data.SceneIndex = filepath.Join(t.TempDir(), "scenes.json")
+
+	index := &SceneIndex{Scenes: []state.SceneMetadata{}}
+
+	const writers, perWriter = 4, 25
+	ids := make(chan uuid.UUID, writers*perWriter)
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				id := uuid.New()
+				index.AddScene(state.SceneMetadata{ID: id, BlendFiles: []string{"scene.blend"}}, &cfg)
+				ids <- id
+			}
+		}()
+	}
+
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < writers*perWriter; i++ {
+				index.FindSceneById(uuid.New())
+				_ = len(index.AllScenes())
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(ids)
+
+	for id := range ids {
+		if index.FindSceneById(id) == nil {
+			t.Errorf("scene %s was lost", id)
+		}
+	}
+	if count := len(index.AllScenes()); count != writers*perWriter {
+		t.Errorf("expected %d scenes, got %d", writers*perWriter, count)
+	}
+}
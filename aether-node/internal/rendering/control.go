@@ -0,0 +1,119 @@
+package rendering
+
+import (
+	"errors"
+	"node/internal/config"
+	"node/internal/state"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var ErrNotRendering = errors.New("the node is not rendering")
+
+// Request cancellation of the current render job. Blender is killed right away if it is already running,
+// otherwise the job stops as soon as its workspace is prepared.
+func CancelRender(nodeState *state.State, keepPartial bool) error {
+	var process *os.Process
+	var jobId string
+
+	running := nodeState.UpdateRenderer(func(renderState *state.RendererState) {
+		renderState.KeepPartial = keepPartial
+		renderState.Cancelled = true
+		process = renderState.Process
+		jobId = renderState.JobID.String()
+	})
+
+	if !running {
+		return ErrNotRendering
+	}
+
+	if process == nil {
+		return nil
+	}
+
+	logrus.Infof("Killing blender process (PID %d) of job %s\n", process.Pid, jobId)
+	return terminateProcess(process)
+}
+
+// Either keep the frames rendered so far as a partial result or discard the workspace
+func finishCancelledRender(cfg *config.NodeConfig, aetherDir string, nodeState *state.State) {
+	renderState := nodeState.Renderer()
+	logrus.Infof("Job %s was cancelled\n", renderState.JobID)
+
+	if !renderState.KeepPartial {
+		_ = cleanupJobOutput(cfg, renderState.JobID)
+		return
+	}
+
+	err := collectResults(cfg, aetherDir, nodeState)
+	if err != nil {
+		logrus.Errorf("Could not collect partial results: %s\n", err)
+	}
+}
+
+var ErrNotStarted = errors.New("blender has not been started yet")
+var ErrAlreadyPaused = errors.New("the render is already paused")
+var ErrNotPaused = errors.New("the render is not paused")
+
+// Suspend the Blender process of the current render job
+func PauseRender(nodeState *state.State) error {
+	var err error
+
+	running := nodeState.UpdateRenderer(func(renderState *state.RendererState) {
+		if renderState.Process == nil {
+			err = ErrNotStarted
+			return
+		}
+		if renderState.Paused {
+			err = ErrAlreadyPaused
+			return
+		}
+
+		if err = suspendProcess(renderState.Process); err != nil {
+			return
+		}
+
+		renderState.Paused = true
+		renderState.PausedAt = time.Now()
+
+		logrus.Infof("Paused job %s\n", renderState.JobID)
+	})
+
+	if !running {
+		return ErrNotRendering
+	}
+
+	return err
+}
+
+// Continue the Blender process of the current render job after it was paused
+func ResumeRender(nodeState *state.State) error {
+	var err error
+
+	running := nodeState.UpdateRenderer(func(renderState *state.RendererState) {
+		if !renderState.Paused {
+			err = ErrNotPaused
+			return
+		}
+
+		if err = resumeProcess(renderState.Process); err != nil {
+			return
+		}
+
+		pausedFor := time.Since(renderState.PausedAt)
+		renderState.PausedTotal += pausedFor
+		renderState.FramePaused += pausedFor
+		renderState.Paused = false
+		renderState.LastOutputAt = time.Now()
+
+		logrus.Infof("Resumed job %s after %s\n", renderState.JobID, pausedFor.Round(time.Second))
+	})
+
+	if !running {
+		return ErrNotRendering
+	}
+
+	return err
+}
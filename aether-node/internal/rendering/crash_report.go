@@ -0,0 +1,76 @@
+package rendering
+
+import (
+	"io"
+	"node/internal/config"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Blender announces its crash report with a line like "Writing: /tmp/shot.crash.txt"
+var crashReportRegex = regexp.MustCompile(`^Writing: (.+\.crash\.txt)\s*$`)
+
+// Location of the crash report announced by an output line
+func parseCrashReportLine(line string) (string, bool) {
+	matches := crashReportRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return "", false
+	}
+	return matches[1], true
+}
+
+// Where Blender puts the crash report of a file when it did not announce it: "<temp dir>/<file name>.crash.txt"
+func defaultCrashReportPath(blendFile string) string {
+	name := strings.TrimSuffix(filepath.Base(blendFile), filepath.Ext(blendFile)) + ".crash.txt"
+	return filepath.Join(os.TempDir(), name)
+}
+
+// Location of the crash report of a job
+func CrashReportPath(cfg *config.NodeConfig, jobId uuid.UUID) string {
+	return filepath.Join(cfg.Data.OutputDirectory, jobId.String()+".crash.txt")
+}
+
+// Copy the crash report of a crashed Blender next to the job's results, since Blender overwrites it on the next
+// crash of the same file. Reports older than the Blender process belong to an earlier crash.
+// Returns the location of the copy, or an empty string if there is no report.
+func collectCrashReport(cfg *config.NodeConfig, jobId uuid.UUID, announced string, blendFile string, startedAt time.Time) string {
+	src := announced
+	if src == "" {
+		src = defaultCrashReportPath(blendFile)
+	}
+
+	info, err := os.Stat(src)
+	if err != nil || info.ModTime().Before(startedAt) {
+		logrus.Warnf("Blender did not leave a crash report for job %s\n", jobId)
+		return ""
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		logrus.Errorf("Could not open crash report (%s): %s\n", src, err)
+		return ""
+	}
+	defer in.Close()
+
+	dst := CrashReportPath(cfg, jobId)
+	out, err := os.Create(dst)
+	if err != nil {
+		logrus.Errorf("Could not store crash report (%s): %s\n", dst, err)
+		return ""
+	}
+	defer out.Close()
+
+	if _, err = io.Copy(out, in); err != nil {
+		logrus.Errorf("Could not store crash report (%s): %s\n", dst, err)
+		return ""
+	}
+
+	logrus.Infof("Stored crash report of job %s: %s\n", jobId, dst)
+	return dst
+}
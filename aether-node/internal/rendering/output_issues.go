@@ -0,0 +1,89 @@
+package rendering
+
+import (
+	"node/internal/state"
+	"regexp"
+	"strings"
+)
+
+// Number of issue messages kept per job; All issues are counted regardless
+const maxOutputIssues = 100
+
+var (
+	warningLineRegex = regexp.MustCompile(`^(?:Warning|WARNING|WARN)\b\s*(?:\([^)]*\))?:?`)
+	errorLineRegex   = regexp.MustCompile(`^(?:Error|ERROR)\b\s*(?:\([^)]*\))?:?`)
+)
+
+// Messages Blender prints on every run, which say nothing about the rendered frames
+var benignIssuePrefixes = []string{
+	"Error: Not freed memory blocks",
+}
+
+// Picks warnings, errors and Python tracebacks out of Blender's output. Tracebacks span several lines,
+// so an issue is only returned once its last line has been seen.
+type issueScanner struct {
+	traceback []string
+	frame     *int
+}
+
+func (scanner *issueScanner) Scan(line string, frame *int) *state.OutputIssue {
+	if scanner.traceback != nil {
+		scanner.traceback = append(scanner.traceback, line)
+
+		// The stack frames are indented, the exception that ends the traceback is not
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			return nil
+		}
+		return scanner.Flush()
+	}
+
+	if strings.Contains(line, "Traceback (most recent call last):") {
+		scanner.traceback = []string{line}
+		scanner.frame = frame
+		return nil
+	}
+
+	for _, prefix := range benignIssuePrefixes {
+		if strings.HasPrefix(line, prefix) {
+			return nil
+		}
+	}
+
+	if errorLineRegex.MatchString(line) {
+		return &state.OutputIssue{Kind: state.IssueError, Message: line, Frame: frame}
+	}
+	if warningLineRegex.MatchString(line) {
+		return &state.OutputIssue{Kind: state.IssueWarning, Message: line, Frame: frame}
+	}
+
+	return nil
+}
+
+// Return a traceback that is still incomplete because the output ended
+func (scanner *issueScanner) Flush() *state.OutputIssue {
+	if scanner.traceback == nil {
+		return nil
+	}
+
+	lines := scanner.traceback
+	scanner.traceback = nil
+	return &state.OutputIssue{
+		Kind:    state.IssueTraceback,
+		Message: lines[len(lines)-1],
+		Details: strings.Join(lines, "\n"),
+		Frame:   scanner.frame,
+	}
+}
+
+// Count an issue and keep its message while there is room for it
+func addOutputIssue(renderState *state.RendererState, issue state.OutputIssue) {
+	if issue.Kind == state.IssueWarning {
+		renderState.WarningCount++
+	} else {
+		renderState.ErrorCount++
+	}
+
+	if len(renderState.Issues) < maxOutputIssues {
+		renderState.Issues = append(renderState.Issues, issue)
+	}
+}
@@ -0,0 +1,180 @@
+package rendering
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Progress information from a single status line of Blender. Depending on the engine and Blender version
+// only some of the fields are present, for example:
+//
+//	Fra:1 Mem:85.42M (Peak 86.02M) | Time:00:03.21 | Remaining:00:10.50 | Mem:12.00M, Peak:12.00M | Scene, ViewLayer | Sample 37/128
+//	Fra:1 Mem:85.42M (Peak 86.02M) | Time:00:03.21 | Mem:12.00M, Peak:12.00M | Scene, ViewLayer | Sample 0/128
+//	Fra:1 Mem:24.50M (Peak 25.10M) | Time:00:00.94 | Rendering 12 / 64 samples
+//	Fra:1 Mem:18.00M (Peak 18.00M) | Time:00:07.10 | Remaining:00:30.10 | Mem:9.00M, Peak:9.00M | Scene, RenderLayer | Path Tracing Tile 12/135
+//	Fra:1 Mem:18.00M (Peak 18.00M) | Time:00:07.10 | Remaining:00:30.10 | Mem:9.00M, Peak:9.00M | Scene, RenderLayer | Rendered 12/135 Tiles, Sample 32/32
+type blenderStatus struct {
+	Frame        int
+	Elapsed      float64
+	HasElapsed   bool
+	Remaining    float64
+	HasRemaining bool
+	// Largest peak memory mentioned on the line, in megabytes
+	PeakMemory float64
+	Sample     int
+	Samples    int
+	Tile       int
+	Tiles      int
+	// "Rendered 12/135 Tiles" counts finished tiles, "Path Tracing Tile 12/135" names the tile in progress
+	TileFinished bool
+}
+
+var (
+	statusFrameRegex     = regexp.MustCompile(`\bFra:\s*(-?\d+)`)
+	statusTimeRegex      = regexp.MustCompile(`\bTime:\s*(\d+(?::\d+){0,2}(?:\.\d+)?)`)
+	statusRemainingRegex = regexp.MustCompile(`\bRemaining:\s*(\d+(?::\d+){0,2}(?:\.\d+)?)`)
+	statusPeakRegex      = regexp.MustCompile(`\bPeak[:\s]\s*(\d+(?:\.\d+)?)([KMGT]?)`)
+	statusSampleRegex    = regexp.MustCompile(`\bSample (\d+)\s*/\s*(\d+)`)
+	statusSamplesRegex   = regexp.MustCompile(`\bRendering (\d+)\s*/\s*(\d+) samples`)
+	statusTileRegex      = regexp.MustCompile(`\bTile (\d+)\s*/\s*(\d+)`)
+	statusTilesRegex     = regexp.MustCompile(`\bRendered (\d+)\s*/\s*(\d+) Tiles`)
+)
+
+// Parse a duration like "01:02:03.45", "02:03.45" or "3.45" into seconds
+func parseTime(s string) float64 {
+	parts := strings.Split(s, ":")
+	factors := []float64{1.0, 60.0, 3600.0}
+	partCount := len(parts)
+	if partCount < 1 || partCount > 3 {
+		return math.NaN()
+	}
+	var seconds float64 = 0.0
+	for i := 0; i < partCount; i++ {
+		f, err := strconv.ParseFloat(parts[partCount-1-i], 64)
+		if err != nil {
+			return math.NaN()
+		}
+		seconds = seconds + f*factors[i]
+	}
+
+	return seconds
+}
+
+// Convert a memory amount as printed by Blender ("85.42M") to megabytes
+func parseMemory(value string, unit string) float64 {
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+
+	switch unit {
+	case "K":
+		return amount / 1024
+	case "G":
+		return amount * 1024
+	case "T":
+		return amount * 1024 * 1024
+	default:
+		return amount
+	}
+}
+
+// Read both numbers of a "current/total" counter
+func parseCounter(re *regexp.Regexp, line string) (int, int, bool) {
+	matches := re.FindStringSubmatch(line)
+	if matches == nil {
+		return 0, 0, false
+	}
+
+	current, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	total, err := strconv.Atoi(matches[2])
+	if err != nil {
+		return 0, 0, false
+	}
+	return current, total, true
+}
+
+// Parse a status line of Blender. Lines that do not name a frame are not status lines.
+func parseBlenderStatus(line string) (blenderStatus, bool) {
+	var status blenderStatus
+
+	matches := statusFrameRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return status, false
+	}
+
+	frame, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return status, false
+	}
+	status.Frame = frame
+
+	if matches := statusTimeRegex.FindStringSubmatch(line); matches != nil {
+		if elapsed := parseTime(matches[1]); !math.IsNaN(elapsed) {
+			status.Elapsed = elapsed
+			status.HasElapsed = true
+		}
+	}
+
+	if matches := statusRemainingRegex.FindStringSubmatch(line); matches != nil {
+		if remaining := parseTime(matches[1]); !math.IsNaN(remaining) {
+			status.Remaining = remaining
+			status.HasRemaining = true
+		}
+	}
+
+	for _, matches := range statusPeakRegex.FindAllStringSubmatch(line, -1) {
+		status.PeakMemory = math.Max(status.PeakMemory, parseMemory(matches[1], matches[2]))
+	}
+
+	if sample, samples, ok := parseCounter(statusSampleRegex, line); ok {
+		status.Sample, status.Samples = sample, samples
+	} else if sample, samples, ok := parseCounter(statusSamplesRegex, line); ok {
+		status.Sample, status.Samples = sample, samples
+	}
+
+	if tile, tiles, ok := parseCounter(statusTileRegex, line); ok {
+		status.Tile, status.Tiles = tile, tiles
+	} else if tile, tiles, ok := parseCounter(statusTilesRegex, line); ok {
+		status.Tile, status.Tiles = tile, tiles
+		status.TileFinished = true
+	}
+
+	return status, true
+}
+
+// Progress of the frame in percent. Blender's own estimate of the remaining time is preferred,
+// otherwise the sample and tile counters are used. Returns false if the line says nothing about the progress.
+func (status *blenderStatus) percent() (float64, bool) {
+	if status.HasElapsed && status.HasRemaining {
+		if status.Elapsed+status.Remaining <= 0 {
+			return 0, true
+		}
+		return status.Elapsed / (status.Elapsed + status.Remaining) * 100, true
+	}
+
+	var sampleFraction float64
+	hasSamples := status.Samples > 0
+	if hasSamples {
+		sampleFraction = math.Min(float64(status.Sample)/float64(status.Samples), 1)
+	}
+
+	if status.Tiles > 0 {
+		done := float64(status.Tile)
+		if !status.TileFinished {
+			done = float64(status.Tile-1) + sampleFraction
+		}
+		return math.Min(math.Max(done/float64(status.Tiles), 0), 1) * 100, true
+	}
+
+	if hasSamples {
+		return sampleFraction * 100, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,256 @@
+package rendering
+
+import (
+	"bufio"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParseBlenderStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		status  blenderStatus
+		percent float64
+		// Whether the line says anything about the progress
+		progress bool
+	}{
+		{
+			name:     "cycles with remaining time",
+			line:     "Fra:1 Mem:101.46M (Peak 101.46M) | Time:00:03.14 | Remaining:00:02.66 | Mem:80.55M, Peak:80.55M | Scene, ViewLayer | Sample 32/64",
+			status:   blenderStatus{Frame: 1, Elapsed: 3.14, HasElapsed: true, Remaining: 2.66, HasRemaining: true, PeakMemory: 101.46, Sample: 32, Samples: 64},
+			percent:  3.14 / 5.8 * 100,
+			progress: true,
+		},
+		{
+			name:     "cycles before the first sample",
+			line:     "Fra:1 Mem:101.46M (Peak 101.46M) | Time:00:00.48 | Mem:80.55M, Peak:80.55M | Scene, ViewLayer | Sample 0/64",
+			status:   blenderStatus{Frame: 1, Elapsed: 0.48, HasElapsed: true, PeakMemory: 101.46, Sample: 0, Samples: 64},
+			percent:  0,
+			progress: true,
+		},
+		{
+			name:     "cycles last sample",
+			line:     "Fra:1 Mem:101.46M (Peak 152.44M) | Time:00:05.79 | Mem:80.55M, Peak:80.55M | Scene, ViewLayer | Sample 64/64",
+			status:   blenderStatus{Frame: 1, Elapsed: 5.79, HasElapsed: true, PeakMemory: 152.44, Sample: 64, Samples: 64},
+			percent:  100,
+			progress: true,
+		},
+		{
+			name:   "cycles synchronizing",
+			line:   "Fra:1 Mem:20.91M (Peak 21.13M) | Time:00:00.08 | Mem:0.00M, Peak:0.00M | Scene, ViewLayer | Synchronizing object | Cube",
+			status: blenderStatus{Frame: 1, Elapsed: 0.08, HasElapsed: true, PeakMemory: 21.13},
+		},
+		{
+			name:   "cycles building bvh",
+			line:   "Fra:1 Mem:21.20M (Peak 21.20M) | Time:00:00.11 | Mem:0.15M, Peak:0.15M | Scene, ViewLayer | Updating Geometry BVH Cube 2/2 | Building BVH 0%",
+			status: blenderStatus{Frame: 1, Elapsed: 0.11, HasElapsed: true, PeakMemory: 21.20},
+		},
+		{
+			name:     "cycles tiles longer than an hour",
+			line:     "Fra:240 Mem:2212.73M (Peak 2224.23M) | Time:34:01.90 | Remaining:01:14:42.50 | Mem:1840.26M, Peak:1851.76M | Scene, ViewLayer | Rendered 1/4 Tiles, Sample 256/1024",
+			status:   blenderStatus{Frame: 240, Elapsed: 2041.9, HasElapsed: true, Remaining: 4482.5, HasRemaining: true, PeakMemory: 2224.23, Sample: 256, Samples: 1024, Tile: 1, Tiles: 4, TileFinished: true},
+			percent:  2041.9 / (2041.9 + 4482.5) * 100,
+			progress: true,
+		},
+		{
+			name:     "cycles tiles without remaining time",
+			line:     "Fra:240 Mem:2212.73M (Peak 2212.73M) | Time:00:04.40 | Mem:1840.26M, Peak:1840.26M | Scene, ViewLayer | Rendered 0/4 Tiles, Sample 0/1024",
+			status:   blenderStatus{Frame: 240, Elapsed: 4.4, HasElapsed: true, PeakMemory: 2212.73, Sample: 0, Samples: 1024, Tile: 0, Tiles: 4, TileFinished: true},
+			percent:  0,
+			progress: true,
+		},
+		{
+			name:     "cycles all tiles rendered",
+			line:     "Fra:240 Mem:2212.73M (Peak 2261.83M) | Time:01:48:44.40 | Mem:1840.26M, Peak:1874.76M | Scene, ViewLayer | Rendered 4/4 Tiles",
+			status:   blenderStatus{Frame: 240, Elapsed: 6524.4, HasElapsed: true, PeakMemory: 2261.83, Tile: 4, Tiles: 4, TileFinished: true},
+			percent:  100,
+			progress: true,
+		},
+		{
+			name:     "cycles tile in progress",
+			line:     "Fra:1 Mem:18.00M (Peak 18.00M) | Time:00:07.10 | Mem:9.00M, Peak:9.00M | Scene, RenderLayer | Path Tracing Tile 12/135, Sample 16/32",
+			status:   blenderStatus{Frame: 1, Elapsed: 7.1, HasElapsed: true, PeakMemory: 18, Sample: 16, Samples: 32, Tile: 12, Tiles: 135},
+			percent:  11.5 / 135 * 100,
+			progress: true,
+		},
+		{
+			name:   "compositor tiles are not render tiles",
+			line:   "Fra:13 Mem:251.67M (Peak 261.37M) | Time:00:11.12 | Compositing | Tile 2-4",
+			status: blenderStatus{Frame: 13, Elapsed: 11.12, HasElapsed: true, PeakMemory: 261.37},
+		},
+		{
+			name:     "eevee samples",
+			line:     "Fra:2 Mem:57.26M (Peak 66.02M) | Time:00:00.51 | Rendering 16 / 64 samples",
+			status:   blenderStatus{Frame: 2, Elapsed: 0.51, HasElapsed: true, PeakMemory: 66.02, Sample: 16, Samples: 64},
+			percent:  25,
+			progress: true,
+		},
+		{
+			name:   "eevee next shader compilation",
+			line:   "Fra:-2 Mem:93.51M (Peak 94.11M) | Time:00:00.21 | Compiling shaders 12 / 14",
+			status: blenderStatus{Frame: -2, Elapsed: 0.21, HasElapsed: true, PeakMemory: 94.11},
+		},
+		{
+			name:   "scene statistics",
+			line:   "Fra:1 Mem:20.91M (Peak 152.44M) | Time:00:05.81 | Sce: Scene Ve:0 Fa:0 La:0",
+			status: blenderStatus{Frame: 1, Elapsed: 5.81, HasElapsed: true, PeakMemory: 152.44},
+		},
+		{
+			name:     "memory in gigabytes",
+			line:     "Fra:3 Mem:1.50G (Peak 2.25G) | Time:00:01.00 | Rendering 1 / 4 samples",
+			status:   blenderStatus{Frame: 3, Elapsed: 1, HasElapsed: true, PeakMemory: 2304, Sample: 1, Samples: 4},
+			percent:  25,
+			progress: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status, ok := parseBlenderStatus(test.line)
+			if !ok {
+				t.Fatalf("not recognized as a status line")
+			}
+
+			// Compare the floats separately, they are parsed from decimal strings
+			got, want := status, test.status
+			if math.Abs(got.Elapsed-want.Elapsed) > 1e-6 || math.Abs(got.Remaining-want.Remaining) > 1e-6 || math.Abs(got.PeakMemory-want.PeakMemory) > 1e-6 {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+			got.Elapsed, got.Remaining, got.PeakMemory = 0, 0, 0
+			want.Elapsed, want.Remaining, want.PeakMemory = 0, 0, 0
+			if got != want {
+				t.Errorf("got %+v, want %+v", status, test.status)
+			}
+
+			percent, progress := status.percent()
+			if progress != test.progress || math.Abs(percent-test.percent) > 1e-6 {
+				t.Errorf("percent() = %f, %t, want %f, %t", percent, progress, test.percent, test.progress)
+			}
+		})
+	}
+}
+
+func TestParseBlenderStatusIgnoresOtherLines(t *testing.T) {
+	lines := []string{
+		"Read blend: \"/srv/aether/workspace/scenes/3f9a/shots/sh010.blend\"",
+		"Saved: '/srv/aether/workspace/jobs/7c1e/aether-frame_0001.png'",
+		" Time: 00:05.94 (Saving: 00:00.13)",
+		"Warning: Unable to open image /tex/wood.png",
+		"Blender quit",
+		"",
+	}
+
+	for _, line := range lines {
+		if status, ok := parseBlenderStatus(line); ok {
+			t.Errorf("%q parsed as status line: %+v", line, status)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	tests := map[string]float64{
+		"3.45":        3.45,
+		"00:05.79":    5.79,
+		"34:01.90":    2041.9,
+		"01:48:44.40": 6524.4,
+	}
+
+	for input, want := range tests {
+		if got := parseTime(input); math.Abs(got-want) > 1e-6 {
+			t.Errorf("parseTime(%q) = %f, want %f", input, got, want)
+		}
+	}
+
+	for _, input := range []string{"", "1:2:3:4", "00:xx"} {
+		if got := parseTime(input); !math.IsNaN(got) {
+			t.Errorf("parseTime(%q) = %f, want NaN", input, got)
+		}
+	}
+}
+
+// Run complete logs through the parser the way invokeBlender does
+func TestParseBlenderLogs(t *testing.T) {
+	tests := []struct {
+		file    string
+		frames  []int
+		samples int
+		tiles   int
+		// Largest peak memory of the log in megabytes
+		peakMemory float64
+	}{
+		{file: "synthetic-cycles.log", frames: []int{1, 2}, samples: 64, peakMemory: 152.44},
+		{file: "synthetic-cycles-tiles.log", frames: []int{240}, samples: 1024, tiles: 4, peakMemory: 2261.83},
+		{file: "synthetic-eevee.log", frames: []int{1, 2, 3}, samples: 64, peakMemory: 66.02},
+		{file: "synthetic-workbench.log", frames: []int{1}, samples: 8, peakMemory: 29.85},
+		{file: "synthetic-cycles-compositor.log", frames: []int{12, 13}, samples: 128, peakMemory: 261.37},
+		{file: "synthetic-eevee-shaders.log", frames: []int{-2, -1}, samples: 16, peakMemory: 140.77},
+		{file: "synthetic-workbench-single-sample.log", frames: []int{1, 2}, samples: 1, peakMemory: 33.40},
+	}
+
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			file, err := os.Open(filepath.Join("testdata", test.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+
+			var frames []int
+			var samples, tiles int
+			var peakMemory float64
+			// Progress of the last line in each frame that reports any
+			lastPercent := map[int]float64{}
+
+			scanner := bufio.NewScanner(file)
+			for scanner.Scan() {
+				status, ok := parseBlenderStatus(scanner.Text())
+				if !ok {
+					continue
+				}
+
+				if !slices.Contains(frames, status.Frame) {
+					frames = append(frames, status.Frame)
+				}
+				if status.Samples > 0 {
+					samples = status.Samples
+				}
+				if status.Tiles > 0 {
+					tiles = status.Tiles
+				}
+				peakMemory = math.Max(peakMemory, status.PeakMemory)
+
+				if percent, ok := status.percent(); ok {
+					if percent < 0 || percent > 100 {
+						t.Errorf("progress %f out of range: %s", percent, scanner.Text())
+					}
+					lastPercent[status.Frame] = percent
+				}
+			}
+			if err := scanner.Err(); err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(frames, test.frames) {
+				t.Errorf("frames = %v, want %v", frames, test.frames)
+			}
+			if samples != test.samples {
+				t.Errorf("samples = %d, want %d", samples, test.samples)
+			}
+			if tiles != test.tiles {
+				t.Errorf("tiles = %d, want %d", tiles, test.tiles)
+			}
+			if math.Abs(peakMemory-test.peakMemory) > 1e-6 {
+				t.Errorf("peak memory = %f, want %f", peakMemory, test.peakMemory)
+			}
+			for _, frame := range test.frames {
+				if percent, ok := lastPercent[frame]; !ok || math.Abs(percent-100) > 1e-6 {
+					t.Errorf("frame %d ends at %f%%, want 100%%", frame, percent)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package rendering
+
+// Number of Blender output lines kept for failure reports
+const outputTailLength = 50
+
+// Fixed-size buffer holding the most recent lines of process output
+type outputTail struct {
+	lines []string
+	next  int
+	full  bool
+}
+
+func newOutputTail(size int) *outputTail {
+	return &outputTail{lines: make([]string, size)}
+}
+
+func (tail *outputTail) Add(line string) {
+	tail.lines[tail.next] = line
+	tail.next = (tail.next + 1) % len(tail.lines)
+	if tail.next == 0 {
+		tail.full = true
+	}
+}
+
+// Return the buffered lines, oldest first
+func (tail *outputTail) Lines() []string {
+	if !tail.full {
+		return append([]string{}, tail.lines[:tail.next]...)
+	}
+	return append(append([]string{}, tail.lines[tail.next:]...), tail.lines[:tail.next]...)
+}
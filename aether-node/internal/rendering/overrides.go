@@ -0,0 +1,120 @@
+package rendering
+
+import (
+	"node/internal/dto/render"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Printed by the scene script, since only image sequences report every frame with a "Saved:" line
+const (
+	imageOutputLine = "Aether: image output"
+	movieOutputLine = "Aether: movie output"
+)
+
+// Build the Python script that is run against the active scene before rendering. It checks the requested scene and
+// engine, reports the kind of output, applies setting overrides and, for frame sets Blender's animation range can not
+// express, renders the frames itself.
+func sceneScript(req *render.RenderRequest) string {
+	var script strings.Builder
+	script.WriteString("import bpy\n")
+	script.WriteString(selectionScript(req))
+	script.WriteString(overrideScript(req.Overrides))
+	script.WriteString("print(\"" + movieOutputLine + "\" if scene.render.is_movie_format else \"" + imageOutputLine + "\")\n")
+
+	if !req.IsSimpleRange() {
+		script.WriteString(frameLoopScript(req))
+	}
+
+	return script.String()
+}
+
+// Blender only prints a message for an unknown "-S" or "-E" and renders the file's own scene or engine instead
+func selectionScript(req *render.RenderRequest) string {
+	var script strings.Builder
+	if req.Scene != nil {
+		script.WriteString("scene = bpy.data.scenes[" + pythonString(*req.Scene) + "]\n")
+	} else {
+		script.WriteString("scene = bpy.context.scene\n")
+	}
+
+	if req.Engine != nil {
+		engine := pythonString(*req.Engine)
+		script.WriteString("if scene.render.engine != " + engine + ":\n")
+		script.WriteString("    raise RuntimeError(\"Render engine \" + " + engine + " + \" is not available\")\n")
+	}
+
+	return script.String()
+}
+
+// Turn validated setting overrides into Python statements that apply them to `scene`
+func overrideScript(overrides map[string]interface{}) string {
+	keys := make([]string, 0, len(overrides))
+	for key := range overrides {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var script strings.Builder
+	for _, key := range keys {
+		script.WriteString("scene." + key + " = " + pythonLiteral(render.OverrideSpecs[key].Kind, overrides[key]) + "\n")
+	}
+
+	return script.String()
+}
+
+// Location of the scene script of a job. It lives next to the output directory, so it is not part of the result.
+func sceneScriptPath(aetherDir string) string {
+	return filepath.Clean(aetherDir) + ".py"
+}
+
+// Render every selected frame as a still; The output path is taken from the "-o" argument
+func frameLoopScript(req *render.RenderRequest) string {
+	var script strings.Builder
+	script.WriteString("frames = set()\n")
+
+	// Listed frames are merged into ranges where possible, a request may list millions of them
+	listed := slices.Clone(req.Frames)
+	slices.Sort(listed)
+	ranges := append(slices.Clone(req.Ranges()), render.CompactFrames(slices.Compact(listed))...)
+
+	var singles []string
+	for _, r := range ranges {
+		if r.Start == r.End {
+			singles = append(singles, strconv.Itoa(r.Start))
+			continue
+		}
+		script.WriteString("frames.update(range(" + strconv.Itoa(r.Start) + ", " + strconv.Itoa(r.End+1) + ", " + strconv.Itoa(r.StepOrDefault()) + "))\n")
+	}
+
+	if len(singles) > 0 {
+		script.WriteString("frames.update([" + strings.Join(singles, ", ") + "])\n")
+	}
+
+	script.WriteString("for frame in sorted(frames):\n")
+	script.WriteString("    scene.frame_set(frame)\n")
+	script.WriteString("    bpy.ops.render.render(write_still=True)\n")
+
+	return script.String()
+}
+
+func pythonLiteral(kind render.OverrideKind, value interface{}) string {
+	switch kind {
+	case render.OverrideBool:
+		if value.(bool) {
+			return "True"
+		}
+		return "False"
+	case render.OverrideInt:
+		return strconv.FormatInt(int64(value.(float64)), 10)
+	default:
+		return strconv.FormatFloat(value.(float64), 'g', -1, 64)
+	}
+}
+
+// Go's quoting only uses escapes that Python string literals understand as well
+func pythonString(value string) string {
+	return strconv.Quote(value)
+}
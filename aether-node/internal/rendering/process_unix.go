@@ -0,0 +1,38 @@
+//go:build !windows
+
+package rendering
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// Run Blender in its own process group so it can be signalled together with its children
+func configureProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// Kill the whole process group of the given process
+func terminateProcess(process *os.Process) error {
+	return syscall.Kill(-process.Pid, syscall.SIGKILL)
+}
+
+// Stop the whole process group of the given process until it is continued
+func suspendProcess(process *os.Process) error {
+	return syscall.Kill(-process.Pid, syscall.SIGSTOP)
+}
+
+// Continue a process group that was stopped with suspendProcess
+func resumeProcess(process *os.Process) error {
+	return syscall.Kill(-process.Pid, syscall.SIGCONT)
+}
+
+// Name of the signal that terminated the process, or an empty string if it exited normally
+func terminationSignal(processState *os.ProcessState) string {
+	status, ok := processState.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return ""
+	}
+	return status.Signal().String()
+}
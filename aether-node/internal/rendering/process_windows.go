@@ -0,0 +1,29 @@
+//go:build windows
+
+package rendering
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+func configureProcess(cmd *exec.Cmd) {}
+
+func terminateProcess(process *os.Process) error {
+	return process.Kill()
+}
+
+var errSuspendUnsupported = errors.New("pausing renders is not supported on Windows")
+
+func suspendProcess(process *os.Process) error {
+	return errSuspendUnsupported
+}
+
+func resumeProcess(process *os.Process) error {
+	return errSuspendUnsupported
+}
+
+func terminationSignal(processState *os.ProcessState) string {
+	return ""
+}
@@ -2,8 +2,9 @@ package rendering
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
-	"io/fs"
+	"io"
 	"math"
 	"node/internal/config"
 	"node/internal/dto/render"
@@ -15,164 +16,314 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/schollz/progressbar/v3"
 	"github.com/sirupsen/logrus"
 )
 
-// Decompress scene file into a new directory in the workspace
-func prepareWorkspace(cfg *config.NodeConfig, scene *state.SceneMetadata, req *render.RenderRequest) error {
-	path := cfg.Data.WorkspaceDirectory + "/" + req.ID.String()
+// Longest line of Blender output that is parsed. Output following a longer line is only copied to the job log.
+const maxOutputLineLength = 16 * 1024 * 1024
 
-	if info, err := os.Stat(path); err == nil {
-		if !info.IsDir() {
-			goto createDir
-		}
+var savedFrameRegex = regexp.MustCompile(`^aether-frame_(-?\d+)`)
 
-		logrus.Debugf("Cleaning up previous workspace: %s\n", path)
-		err = os.RemoveAll(path)
-		if err != nil {
-			logrus.Debugf("Failed to remove directory: %s\n", err)
-			goto createDir
-		}
-	}
+// Parse a line like "Saved: '/path/.aether/aether-frame_0001.png'". Files outside the output directory are ignored.
+// The frame number is taken from the file name and falls back to the frame Blender reported last.
+func parseSavedLine(line string, aetherDir string, currentFrame int) (state.SavedFrame, bool) {
+	path := strings.TrimSpace(strings.TrimPrefix(line, "Saved:"))
+	path = strings.Trim(path, "'\"")
 
-createDir:
-	err := os.MkdirAll(path, 0777)
-	if err != nil {
-		logrus.Debugf("Could not create scene directory in workspacee (%s): %s\n", path, err)
-		return err
+	file, err := filepath.Rel(aetherDir, path)
+	if err != nil || file == "." || strings.HasPrefix(file, "..") {
+		return state.SavedFrame{}, false
 	}
 
-	zipPath := cfg.Data.ScenesDirectory + "/" + scene.Filename
-
-	logrus.Debugf("Decompressing (%s) into (%s) ...\n", zipPath, path)
-
-	err = util.DecompressZip(zipPath, path)
-	if err != nil {
-		logrus.Debugf("Could not decompress scene file (%s): %s\n", path, err)
-		return err
+	frame := currentFrame
+	if matches := savedFrameRegex.FindStringSubmatch(filepath.Base(file)); matches != nil {
+		if parsed, err := strconv.Atoi(matches[1]); err == nil {
+			frame = parsed
+		}
 	}
 
-	logrus.Debugf("Decompressing complete.")
-
-	return nil
+	return state.SavedFrame{Frame: frame, File: filepath.ToSlash(file)}, true
 }
 
-func parseTime(s string) float64 {
-	parts := strings.Split(s, ":")
-	factors := []float64{1.0, 60.0, 3600.0}
-	partCount := len(parts)
-	if partCount < 1 || partCount > 3 {
-		return math.NaN()
-	}
-	var seconds float64 = 0.0
-	for i := 0; i < partCount; i++ {
-		f, err := strconv.ParseFloat(parts[partCount-1-i], 64)
-		if err != nil {
-			return math.NaN()
+// Add a saved frame to the list, replacing an earlier file of the same frame
+func addSavedFrame(frames []state.SavedFrame, saved state.SavedFrame) []state.SavedFrame {
+	for i := range frames {
+		if frames[i].Frame == saved.Frame {
+			frames[i] = saved
+			return frames
 		}
-		seconds = seconds + f*factors[i]
 	}
+	return append(frames, saved)
+}
 
-	return seconds
+type BlenderError struct {
+	ExitCode int
+	Signal   string
 }
 
-func parseBlenderOutput(line string) (ok bool, frame int, elapsed float64, remaining float64, progress float64) {
-	progressRe := regexp.MustCompile(`Fra:(\d+).*Time:(\d+:\d+.\d+).*Remaining:(\d+:\d+.\d+)`)
+func (e *BlenderError) Error() string {
+	if e.Signal != "" {
+		return "blender was terminated by signal: " + e.Signal
+	}
+	return "blender exited with code " + strconv.Itoa(e.ExitCode)
+}
 
-	matches := progressRe.FindStringSubmatch(line)
+// Blender exited successfully without writing any output
+var ErrNoOutput = errors.New("blender did not write any output")
 
-	if len(matches) != 4 {
-		return false, -1, math.NaN(), math.NaN(), math.NaN()
-	}
+// Blender exited successfully without saving every requested frame, e.g. because the frame script failed
+type MissingFramesError struct {
+	Frames []int
+}
 
-	currFrame, err := strconv.Atoi(matches[1])
+func (e *MissingFramesError) Error() string {
+	const listed = 20
 
-	if err != nil {
-		return false, -1, math.NaN(), math.NaN(), math.NaN()
+	var parts []string
+	for _, frame := range e.Frames[:min(len(e.Frames), listed)] {
+		parts = append(parts, strconv.Itoa(frame))
 	}
+	if len(e.Frames) > listed {
+		parts = append(parts, "and "+strconv.Itoa(len(e.Frames)-listed)+" more")
+	}
+	return "blender did not render frames " + strings.Join(parts, ", ")
+}
 
-	timeElapsed := parseTime(matches[2])
-	timeRemaining := parseTime(matches[3])
-
-	if timeElapsed == math.NaN() || timeRemaining == math.NaN() {
-		return false, -1, math.NaN(), math.NaN(), math.NaN()
+// Requested frames that have no saved file
+func missingFrames(requested []int, saved []state.SavedFrame) []int {
+	done := make(map[int]bool, len(saved))
+	for _, frame := range saved {
+		done[frame.Frame] = true
 	}
 
-	framePercent := (timeElapsed / (timeElapsed + timeRemaining)) * 100
+	var missing []int
+	for _, frame := range requested {
+		if !done[frame] {
+			missing = append(missing, frame)
+		}
+	}
+	return missing
+}
 
-	return true, currFrame, timeElapsed, timeRemaining, framePercent
+// Whether Blender wrote anything into the output directory, for output that is not checked frame by frame
+func hasOutput(aetherDir string) bool {
+	entries, err := os.ReadDir(aetherDir)
+	return err == nil && len(entries) > 0
 }
 
-func collectResults(cfg *config.NodeConfig, aetherDir string, req *render.RenderRequest) error {
-	dst := filepath.Join(cfg.Data.OutputDirectory, req.ID.String()+".zip")
+// Compress the rendered frames into a result file named after the job
+func collectResults(cfg *config.NodeConfig, aetherDir string, nodeState *state.State) error {
+	nodeState.SetPhase(state.PhaseCollecting)
+	renderState := nodeState.Renderer()
 
-	// Remove previous results file
-	_ = os.RemoveAll(dst)
+	dst := filepath.Join(cfg.Data.OutputDirectory, renderState.JobID.String()+".zip")
 
 	err := util.CompressZip(aetherDir, dst)
 	if err != nil {
 		return err
 	}
 
+	nodeState.UpdateRenderer(func(renderState *state.RendererState) {
+		renderState.OutputPath = dst
+	})
 	logrus.Infof("Collected render results to: %s", dst)
 
-	// Delete workspace directory
-	path := filepath.Join(cfg.Data.WorkspaceDirectory, req.ID.String())
-	err = os.RemoveAll(path)
+	return cleanupJobOutput(cfg, renderState.JobID)
+}
+
+// Keep whatever frames were rendered before Blender failed, otherwise just clean up
+func finishFailedRender(cfg *config.NodeConfig, aetherDir string, nodeState *state.State) {
+	renderState := nodeState.Renderer()
+	entries, err := os.ReadDir(aetherDir)
+	if err != nil || len(entries) == 0 {
+		_ = cleanupJobOutput(cfg, renderState.JobID)
+		return
+	}
+
+	logrus.Infof("Collecting %d partial results of failed job %s\n", len(entries), renderState.JobID)
+	err = collectResults(cfg, aetherDir, nodeState)
 	if err != nil {
-		logrus.Errorf("Could not remove workspace directory (%s): %s\n", path, err)
-		return err
+		logrus.Errorf("Could not collect partial results: %s\n", err)
 	}
+}
 
-	logrus.Debugf("Removed workspace directory: %s", path)
-	return nil
+// Location of the Blender output log of a job
+func JobLogPath(cfg *config.NodeConfig, jobId uuid.UUID) string {
+	return filepath.Join(cfg.Data.OutputDirectory, jobId.String()+".log")
+}
+
+// Build the Blender command line. Blender evaluates its arguments in order, so all settings must precede "-a".
+// Frame sets that Blender's animation range can not express are rendered by the scene script instead of "-a".
+// The scene script is passed as a file, since a single argument is limited in size (128 KiB on Linux).
+func blenderArguments(file string, aetherDir string, scriptPath string, req *render.RenderRequest) []string {
+	args := []string{"-b", file}
+
+	// The scene has to be selected first, since the following options apply to the active scene
+	if req.Scene != nil {
+		args = append(args, "-S", *req.Scene)
+	}
+
+	if req.Engine != nil {
+		args = append(args, "-E", *req.Engine)
+	}
+
+	if req.IsSimpleRange() {
+		frames := req.Ranges()[0]
+		args = append(args,
+			"-s", strconv.Itoa(frames.Start),
+			"-e", strconv.Itoa(frames.End),
+			"-j", strconv.Itoa(frames.StepOrDefault()),
+		)
+	}
+
+	if req.OutputFormat != nil {
+		args = append(args, "-F", *req.OutputFormat)
+	}
+
+	args = append(args, "-o", filepath.Join(aetherDir, "aether-frame_####"))
+
+	// Blender ignores exceptions of scripts unless told otherwise, a failing check or override would render anyway
+	args = append(args, "--python-exit-code", "1", "--python", scriptPath)
+
+	if req.IsSimpleRange() {
+		args = append(args, "-a")
+	}
+
+	return args
 }
 
-func invokeBlender(file string, aetherDir string, state *state.State, cfg *config.NodeConfig, req *render.RenderRequest) {
-	// Mark the node as not busy once this function exits
-	defer state.RenderLock.Unlock()
+func invokeBlender(file string, aetherDir string, nodeState *state.State, cfg *config.NodeConfig, req *render.RenderRequest) error {
+	scriptPath := sceneScriptPath(aetherDir)
+	if err := os.WriteFile(scriptPath, []byte(sceneScript(req)), 0644); err != nil {
+		logrus.Errorf("Could not write scene script: %s\n", err)
+		return err
+	}
 
-	cmd := exec.Command(
-		cfg.Node.Blender,
-		"-b", file,
-		"-s", strconv.Itoa(int(*req.FrameStart)),
-		"-e", strconv.Itoa(int(*req.FrameEnd)),
-		"-o", filepath.Join(aetherDir, "aether-frame_####"),
-		"-a",
-	)
+	cmd := exec.Command(nodeState.Renderer().BlenderPath, blenderArguments(file, aetherDir, scriptPath, req)...)
+	configureProcess(cmd)
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		logrus.Errorf("Could not open stdout pipe to blender process: %s\n", err)
-		return
+		return err
 	}
 
 	cmd.Stderr = cmd.Stdout
 
-	logrus.Infof("Starting render process on %s frames %d to %d ...\n", req.ID, *req.FrameStart, *req.FrameEnd)
+	logrus.Infof("Starting render process on %s frames %s ...\n", req.ID, req.DescribeFrames())
 	logrus.Debugf("Invoking: %s\n", cmd.String())
 
 	if err := cmd.Start(); err != nil {
 		logrus.Errorf("Could not invoke blender process: %s\n", err)
-		return
+		return err
 	}
+	blenderStartedAt := time.Now()
+
+	var cancelled bool
+	nodeState.UpdateRenderer(func(renderState *state.RendererState) {
+		renderState.Process = cmd.Process
+		renderState.OutputDir = aetherDir
+		renderState.LastOutputAt = time.Now()
+		cancelled = renderState.Cancelled
+	})
+
+	watchdogDone := make(chan struct{})
+	go watchBlender(cfg, nodeState, req, cmd.Process, watchdogDone)
+	nodeState.SetPhase(state.PhaseRendering)
 
+	// The job might have been cancelled while Blender was starting up
+	if cancelled {
+		_ = terminateProcess(cmd.Process)
+	}
+
+	// Keep the complete output next to the job's results
+	var logWriter io.Writer = io.Discard
+	logFile, err := os.OpenFile(nodeState.Renderer().LogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		logrus.Errorf("Could not open job log: %s\n", err)
+	} else {
+		defer logFile.Close()
+		logWriter = logFile
+	}
+
+	// Frames Blender confirmed as saved, the only ones a resumed job trusts
+	var savedWriter io.Writer = io.Discard
+	savedFile, err := os.OpenFile(savedFramesLog(aetherDir), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		logrus.Errorf("Could not open saved frames log: %s\n", err)
+	} else {
+		defer savedFile.Close()
+		savedWriter = savedFile
+	}
+
+	// Python reprs and tracebacks can make single lines much longer than the scanner's default limit
 	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), maxOutputLineLength)
 
 	var bar *progressbar.ProgressBar = nil
 	lastFrame := -1
+	tail := newOutputTail(outputTailLength)
+	issues := issueScanner{}
+	crashReport := ""
+	imageOutput := req.OutputFormat != nil
 
 	for scanner.Scan() {
 		line := scanner.Text()
+		tail.Add(line)
+		_, _ = fmt.Fprintln(logWriter, line)
 
-		ok, frame, elapsed, remaining, progress := parseBlenderOutput(line)
+		if path, ok := parseCrashReportLine(line); ok {
+			crashReport = path
+		}
+		if line == imageOutputLine {
+			imageOutput = true
+		}
+
+		// A progress bar exists as soon as Blender has reported the first frame
+		var issueFrame *int = nil
+		if bar != nil {
+			frame := lastFrame
+			issueFrame = &frame
+		}
+
+		issue := issues.Scan(line, issueFrame)
+		nodeState.UpdateRenderer(func(renderState *state.RendererState) {
+			renderState.LastOutputAt = time.Now()
+			if issue != nil {
+				addOutputIssue(renderState, *issue)
+			}
+		})
+
+		if strings.HasPrefix(line, "Saved:") {
+			var saved state.SavedFrame
+			var ok bool
+			nodeState.UpdateRenderer(func(renderState *state.RendererState) {
+				renderState.FramePercent = 0
+
+				// Files written elsewhere (e.g. by File Output nodes) and further views of a frame are not counted
+				if saved, ok = parseSavedLine(line, aetherDir, renderState.CurrentFrame); ok {
+					renderState.SavedFrames = addSavedFrame(renderState.SavedFrames, saved)
+					renderState.FramesDone = len(renderState.SavedFrames)
+				}
+			})
+			if ok {
+				_, _ = fmt.Fprintln(savedWriter, saved.File)
+			}
+			continue
+		}
+
+		status, ok := parseBlenderStatus(line)
 		if !ok {
 			continue
 		}
+		frame := status.Frame
 
-		if lastFrame != frame {
+		newFrame := lastFrame != frame
+		if newFrame {
 			// Make sure all frames end on 100%
 			if bar != nil {
 				bar.Set(100)
@@ -183,18 +334,108 @@ func invokeBlender(file string, aetherDir string, state *state.State, cfg *confi
 			bar.RenderBlank()
 		}
 
+		var progress float64
+		nodeState.UpdateRenderer(func(renderState *state.RendererState) {
+			if newFrame {
+				renderState.FramePaused = 0
+				renderState.FramePercent = 0
+				renderState.TimeElapsed = 0
+				renderState.TimeRemaining = 0
+				renderState.Sample, renderState.SampleCount = 0, 0
+				renderState.Tile, renderState.TileCount = 0, 0
+			}
+
+			// Blender measures wall-clock time, which includes the time the frame was paused
+			if paused := renderState.FramePaused.Seconds(); paused > 0 && status.HasElapsed {
+				status.Elapsed = math.Max(status.Elapsed-paused, 0)
+			}
+
+			// Lines without any progress information (e.g. "Synchronizing object") keep the last known progress
+			if percent, known := status.percent(); known {
+				renderState.FramePercent = percent
+			}
+
+			if status.HasElapsed {
+				renderState.TimeElapsed = status.Elapsed
+			}
+			if status.HasRemaining {
+				renderState.TimeRemaining = status.Remaining
+			} else if status.HasElapsed && renderState.FramePercent > 0 {
+				// Extrapolate from the progress when Blender does not estimate the remaining time itself
+				renderState.TimeRemaining = status.Elapsed * (100 - renderState.FramePercent) / renderState.FramePercent
+			}
+
+			if status.Samples > 0 {
+				renderState.Sample, renderState.SampleCount = status.Sample, status.Samples
+			}
+			if status.Tiles > 0 {
+				renderState.Tile, renderState.TileCount = status.Tile, status.Tiles
+			}
+			renderState.PeakMemory = math.Max(renderState.PeakMemory, status.PeakMemory)
+			renderState.CurrentFrame = frame
+			progress = renderState.FramePercent
+		})
+
 		bar.Set(int(progress))
+	}
 
-		state.RendererState.FramePercent = progress
-		state.RendererState.CurrentFrame = frame
-		state.RendererState.TimeElapsed = elapsed
-		state.RendererState.TimeRemaining = remaining
+	// Blender blocks once the pipe is full, so the rest of its output still has to be read
+	if err := scanner.Err(); err != nil {
+		logrus.Errorf("Could not read blender output: %s\n", err)
+		_, _ = io.Copy(logWriter, stdout)
 	}
 
 	err = cmd.Wait()
+	close(watchdogDone)
+
+	if issue := issues.Flush(); issue != nil {
+		nodeState.UpdateRenderer(func(renderState *state.RendererState) {
+			addOutputIssue(renderState, *issue)
+		})
+	}
+
+	exitCode := cmd.ProcessState.ExitCode()
+	signal := terminationSignal(cmd.ProcessState)
+	var jobId uuid.UUID
+	var watchdogReason string
+	nodeState.UpdateRenderer(func(renderState *state.RendererState) {
+		watchdogReason = renderState.WatchdogReason
+		renderState.ExitCode = &exitCode
+		renderState.Signal = signal
+		renderState.OutputTail = tail.Lines()
+		cancelled = renderState.Cancelled
+		jobId = renderState.JobID
+	})
+
+	if cancelled {
+		if bar != nil {
+			fmt.Println()
+		}
+		finishCancelledRender(cfg, aetherDir, nodeState)
+		return nil
+	}
+
 	if err != nil {
-		logrus.Errorf("Could not wait for blender process: %s\n", err)
-		return
+		if bar != nil {
+			fmt.Println()
+		}
+
+		var failure error = &BlenderError{ExitCode: exitCode, Signal: signal}
+		if watchdogReason != "" {
+			failure = &WatchdogError{Reason: watchdogReason}
+		}
+
+		// Blender crashed on its own, rather than being killed by the watchdog
+		if watchdogReason == "" && (signal != "" || crashReport != "") {
+			reportPath := collectCrashReport(cfg, jobId, crashReport, file, blenderStartedAt)
+			nodeState.UpdateRenderer(func(renderState *state.RendererState) {
+				renderState.CrashReport = reportPath
+			})
+		}
+
+		logrus.Errorf("Blender failed on job %s: %s\n", jobId, failure)
+		finishFailedRender(cfg, aetherDir, nodeState)
+		return failure
 	}
 
 	// Make sure the last frame also ends on 100%
@@ -203,54 +444,92 @@ func invokeBlender(file string, aetherDir string, state *state.State, cfg *confi
 		fmt.Println()
 	}
 
-	state.RendererState = nil
+	// Blender can exit successfully without writing every frame, so check that none is missing. Movies are written
+	// without "Saved:" lines, so only their existence can be checked.
+	renderState := nodeState.Renderer()
+	if imageOutput {
+		if missing := missingFrames(renderState.Request.FrameList(), renderState.SavedFrames); len(missing) > 0 {
+			failure := &MissingFramesError{Frames: missing}
+			logrus.Errorf("Blender failed on job %s: %s\n", jobId, failure)
+			finishFailedRender(cfg, aetherDir, nodeState)
+			return failure
+		}
+	} else if !hasOutput(aetherDir) {
+		logrus.Errorf("Blender failed on job %s: %s\n", jobId, ErrNoOutput)
+		finishFailedRender(cfg, aetherDir, nodeState)
+		return ErrNoOutput
+	}
+
 	logrus.Debugf("Blender task finished successfully. Preparing result set")
 
-	err = collectResults(cfg, aetherDir, req)
+	err = collectResults(cfg, aetherDir, nodeState)
 	if err != nil {
 		logrus.Errorf("Could not collect results: %s\n", err)
-		return
+		return err
 	}
 
 	logrus.Infof("Task completed successfully.")
+	return nil
 }
 
-func findBlendFile(cfg *config.NodeConfig, req *render.RenderRequest) string {
-	var blendFile = ""
-	path := filepath.Join(cfg.Data.WorkspaceDirectory, req.ID.String())
-	err := filepath.WalkDir(path, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if !d.IsDir() && filepath.Ext(d.Name()) == ".blend" && blendFile == "" {
-			blendFile = path
-		}
-
-		return nil
-	})
+// Location of the *.blend file to render inside the extracted workspace
+func findBlendFile(workspace string, scene *state.SceneMetadata, req *render.RenderRequest) (string, error) {
+	var requested string
+	if req.BlendFile != nil {
+		requested = *req.BlendFile
+	}
 
+	file, err := scene.SelectBlendFile(requested)
 	if err != nil {
-		logrus.Errorf("Could not find *.blend file in scene (%s): %s\n", req.ID.String(), err)
-		return ""
+		return "", err
+	}
+
+	blendFile := filepath.Join(workspace, filepath.FromSlash(file))
+	if _, err := os.Stat(blendFile); err != nil {
+		return "", fmt.Errorf("could not locate %s in the workspace: %w", file, err)
 	}
 
 	logrus.Debugf("Found blendFile in scene (%s): %s", req.ID, blendFile)
-	return blendFile
+	return blendFile, nil
 }
 
-func InitializeRenderProcess(cfg *config.NodeConfig, state *state.State, req *render.RenderRequest) error {
-	err := prepareWorkspace(cfg, &state.RendererState.Scene, req)
+// Prepare the workspace and render the requested frames. Blocks until Blender has exited.
+func InitializeRenderProcess(cfg *config.NodeConfig, nodeState *state.State, req *render.RenderRequest) error {
+	renderState := nodeState.Renderer()
+	scene := renderState.Scene
+
+	workspace, err := prepareWorkspace(cfg, &scene, func(progress util.ExtractProgress) {
+		nodeState.UpdateRenderer(func(renderState *state.RendererState) {
+			renderState.Preparation = progress
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("could not extract the scene: %w", err)
+	}
+
+	blendFile, err := findBlendFile(workspace, &scene, req)
 	if err != nil {
 		return err
 	}
 
-	var blendFile = findBlendFile(cfg, req)
-	if blendFile == "" {
-		return fmt.Errorf("could not locate *.blend file")
+	if renderState := nodeState.Renderer(); renderState.Cancelled {
+		logrus.Infof("Job %s was cancelled before rendering started\n", renderState.JobID)
+		return cleanupJobOutput(cfg, renderState.JobID)
+	}
+
+	// An interrupted job continues with the frames already in its output directory
+	aetherDir := JobOutputDir(cfg, renderState.JobID)
+	_, statErr := os.Stat(aetherDir)
+	resumed := renderState.Resume && statErr == nil
+	if renderState.Resume && !resumed {
+		logrus.Warnf("Output directory of job %s is gone, rendering all frames again\n", renderState.JobID)
 	}
 
-	var aetherDir = filepath.Join(filepath.Dir(blendFile), ".aether")
+	if !resumed {
+		if err = cleanupJobOutput(cfg, renderState.JobID); err != nil {
+			return err
+		}
+	}
 
 	err = os.MkdirAll(aetherDir, 0777)
 	if err != nil {
@@ -258,7 +537,22 @@ func InitializeRenderProcess(cfg *config.NodeConfig, state *state.State, req *re
 	}
 	logrus.Debugf("Created output directory: %s\n", aetherDir)
 
-	go invokeBlender(blendFile, aetherDir, state, cfg, req)
+	if !resumed {
+		return invokeBlender(blendFile, aetherDir, nodeState, cfg, req)
+	}
 
-	return nil
+	done := existingFrames(aetherDir, req)
+	remaining, missing := remainingRequest(req, done)
+	nodeState.UpdateRenderer(func(renderState *state.RendererState) {
+		renderState.SavedFrames = done
+		renderState.FramesDone = len(done)
+		renderState.OutputDir = aetherDir
+	})
+	logrus.Infof("Resuming job %s: %d frames already exist, %d are missing\n", renderState.JobID, len(done), missing)
+
+	if missing == 0 {
+		return collectResults(cfg, aetherDir, nodeState)
+	}
+
+	return invokeBlender(blendFile, aetherDir, nodeState, cfg, &remaining)
 }
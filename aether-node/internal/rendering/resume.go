@@ -0,0 +1,121 @@
+package rendering
+
+import (
+	"node/internal/config"
+	"node/internal/dto/render"
+	"node/internal/persistence"
+	"node/internal/state"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Log of the frames Blender confirmed with "Saved:", one file name per line. It lives next to the output directory, so it
+// is not part of the result.
+func savedFramesLog(aetherDir string) string {
+	return filepath.Clean(aetherDir) + ".saved"
+}
+
+// Requested frames whose file was confirmed as saved. Blender writes images straight to their final path, so any other
+// file may have been cut off when the node died.
+func existingFrames(aetherDir string, req *render.RenderRequest) []state.SavedFrame {
+	content, err := os.ReadFile(savedFramesLog(aetherDir))
+	if err != nil {
+		return nil
+	}
+
+	confirmed := map[string]bool{}
+	for _, file := range strings.Split(string(content), "\n") {
+		if file != "" {
+			confirmed[file] = true
+		}
+	}
+
+	entries, err := os.ReadDir(aetherDir)
+	if err != nil {
+		return nil
+	}
+
+	requested := req.FrameList()
+	var frames []state.SavedFrame
+	for _, entry := range entries {
+		matches := savedFrameRegex.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || matches == nil || !confirmed[entry.Name()] {
+			continue
+		}
+
+		frame, err := strconv.Atoi(matches[1])
+		if err != nil {
+			continue
+		}
+		if _, found := slices.BinarySearch(requested, frame); !found {
+			continue
+		}
+
+		if info, err := entry.Info(); err != nil || info.Size() == 0 {
+			continue
+		}
+
+		frames = addSavedFrame(frames, state.SavedFrame{Frame: frame, File: entry.Name()})
+	}
+
+	return frames
+}
+
+// Copy of the request that selects only the frames which have not been rendered yet. Runs of missing frames are kept as
+// ranges, so the scene script of a large job stays small.
+func remainingRequest(req *render.RenderRequest, done []state.SavedFrame) (render.RenderRequest, int) {
+	missing := missingFrames(req.FrameList(), done)
+
+	remaining := *req
+	remaining.FrameStart = nil
+	remaining.FrameEnd = nil
+	remaining.FrameStep = nil
+	remaining.Frames = nil
+	remaining.FrameRanges = render.CompactFrames(missing)
+	return remaining, len(missing)
+}
+
+// Queue an interrupted job again. It keeps its output directory and only renders the frames that are still missing.
+// Jobs put in front are rendered before everything else that is waiting.
+func RequeueInterruptedJob(job state.Job, cfg *config.NodeConfig, queue *persistence.JobQueue, jobs *persistence.JobIndex, front bool) int {
+	job.Status = state.JobQueued
+	job.Resume = true
+	job.Error = ""
+	job.QueuedAt = time.Now().UnixNano()
+	jobs.UpdateJob(job, cfg)
+
+	if front {
+		return queue.EnqueueFront(job, cfg)
+	}
+	return queue.Enqueue(job, cfg)
+}
+
+// A job can still be waiting in the queue if the node stopped while starting it. It was marked as interrupted like every
+// other started job, so it is taken out of the queue and resumed like those.
+func DropInterruptedFromQueue(cfg *config.NodeConfig, queue *persistence.JobQueue, jobs *persistence.JobIndex) {
+	for _, pending := range queue.Pending() {
+		if job := jobs.FindJobById(pending.ID); job != nil && job.Status == state.JobInterrupted {
+			logrus.Warnf("Removing interrupted job %s from the queue\n", job.ID)
+			queue.Remove(job.ID, cfg)
+		}
+	}
+}
+
+// Requeue all jobs that were interrupted by the last shutdown, keeping their original order
+func ResumeInterruptedJobs(cfg *config.NodeConfig, queue *persistence.JobQueue, jobs *persistence.JobIndex) {
+	all := jobs.AllJobs()
+	for i := len(all) - 1; i >= 0; i-- {
+		if all[i].Status != state.JobInterrupted {
+			continue
+		}
+
+		logrus.Infof("Resuming interrupted job %s\n", all[i].ID)
+		RequeueInterruptedJob(all[i], cfg, queue, jobs, true)
+	}
+}
@@ -0,0 +1,90 @@
+package rendering
+
+import (
+	"fmt"
+	"node/internal/config"
+	"node/internal/dto/render"
+	"node/internal/state"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Longest interval between two watchdog checks
+const watchdogInterval = 5 * time.Second
+
+type WatchdogError struct {
+	Reason string
+}
+
+func (e *WatchdogError) Error() string {
+	return "watchdog: " + e.Reason
+}
+
+// Effective maximum duration of a job; The stricter of the node and the job limit wins. Zero means unlimited.
+func maxJobDuration(cfg *config.NodeConfig, req *render.RenderRequest) time.Duration {
+	limit := cfg.Watchdog.MaxJobDuration
+	if req.MaxDuration != nil {
+		jobLimit := time.Duration(*req.MaxDuration) * time.Second
+		if limit == 0 || jobLimit < limit {
+			limit = jobLimit
+		}
+	}
+	return limit
+}
+
+// Kill Blender once it exceeds its maximum duration or stops producing output. Paused time counts towards neither.
+// Returns when done is closed.
+func watchBlender(cfg *config.NodeConfig, nodeState *state.State, req *render.RenderRequest, process *os.Process, done <-chan struct{}) {
+	maxDuration := maxJobDuration(cfg, req)
+	stallTimeout := cfg.Watchdog.StallTimeout
+	if maxDuration == 0 && stallTimeout == 0 {
+		return
+	}
+
+	interval := watchdogInterval
+	for _, limit := range []time.Duration{maxDuration / 4, stallTimeout / 4} {
+		if limit > 0 && limit < interval {
+			interval = limit
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// Only the time Blender is running counts, not the workspace preparation
+	baseline := nodeState.Renderer().ActiveTime()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		renderState := nodeState.Renderer()
+		if renderState == nil || renderState.Paused {
+			continue
+		}
+
+		var reason string
+		if running := renderState.ActiveTime() - baseline; maxDuration > 0 && running > maxDuration {
+			reason = fmt.Sprintf("the job exceeded its maximum duration of %s", maxDuration)
+		} else if silent := time.Since(renderState.LastOutputAt); stallTimeout > 0 && silent > stallTimeout {
+			reason = fmt.Sprintf("blender did not produce any output for %s", silent.Round(time.Second))
+		} else {
+			continue
+		}
+
+		logrus.Warnf("Killing blender process of job %s: %s\n", renderState.JobID, reason)
+		nodeState.UpdateRenderer(func(renderState *state.RendererState) {
+			renderState.WatchdogReason = reason
+		})
+
+		if err := terminateProcess(process); err != nil {
+			logrus.Errorf("Could not kill blender process: %s\n", err)
+		}
+		return
+	}
+}
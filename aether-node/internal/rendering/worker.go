@@ -0,0 +1,104 @@
+package rendering
+
+import (
+	"node/internal/blender"
+	"node/internal/config"
+	"node/internal/persistence"
+	"node/internal/state"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Take jobs off the queue and render them one at a time. Never returns.
+func RunWorker(cfg *config.NodeConfig, nodeState *state.State, blenders *blender.Registry, scenes *persistence.SceneIndex, queue *persistence.JobQueue, jobs *persistence.JobIndex) {
+	EvictWorkspaces(cfg)
+
+	for {
+		// The job has to be running before it leaves the queue, otherwise it could neither be cancelled nor recovered
+		job := queue.Next(cfg, func(job *state.Job) {
+			job.Status = state.JobRunning
+			job.StartedAt = time.Now().UnixNano()
+			jobs.UpdateJob(*job, cfg)
+
+			nodeState.StartRender(state.RendererState{JobID: job.ID, Request: job.Request, CurrentFrame: 0, FrameCount: job.Request.FrameCount(), FramePercent: 0.0, StartedAt: time.Now(), Resume: job.Resume})
+		})
+
+		scene := scenes.FindSceneById(*job.Request.ID)
+		if scene == nil {
+			logrus.Errorf("Dropping job %s: Scene %s no longer exists\n", job.ID, job.Request.ID)
+			job.Status = state.JobFailed
+			job.Error = "The scene no longer exists"
+			job.FinishedAt = time.Now().UnixNano()
+			jobs.UpdateJob(job, cfg)
+			nodeState.FinishRender(job)
+			continue
+		}
+
+		// The installation is resolved again, the configuration might have changed since the job was queued
+		var selector string
+		if job.Request.Blender != nil {
+			selector = *job.Request.Blender
+		}
+		installation, err := blenders.Resolve(selector)
+		if err != nil {
+			logrus.Errorf("Dropping job %s: %s\n", job.ID, err)
+			job.Status = state.JobFailed
+			job.Error = "The requested Blender is not installed: " + err.Error()
+			job.FinishedAt = time.Now().UnixNano()
+			jobs.UpdateJob(job, cfg)
+			nodeState.FinishRender(job)
+			continue
+		}
+
+		logrus.Infof("Starting job %s\n", job.ID)
+		nodeState.SetPhase(state.PhasePreparing)
+		job.LogPath = JobLogPath(cfg, job.ID)
+		job.Resume = false
+		jobs.UpdateJob(job, cfg)
+
+		nodeState.UpdateRenderer(func(renderState *state.RendererState) {
+			renderState.Scene = *scene
+			renderState.LogPath = job.LogPath
+			renderState.BlenderPath = installation.Path
+		})
+
+		err = InitializeRenderProcess(cfg, nodeState, &job.Request)
+
+		renderState := nodeState.Renderer()
+		job.FinishedAt = time.Now().UnixNano()
+		job.ExitCode = renderState.ExitCode
+		job.Signal = renderState.Signal
+		job.OutputTail = renderState.OutputTail
+		job.OutputPath = renderState.OutputPath
+		job.CrashReport = renderState.CrashReport
+		job.Issues = renderState.Issues
+		job.WarningCount = renderState.WarningCount
+		job.ErrorCount = renderState.ErrorCount
+
+		// The frames can only be downloaded from the result file once the workspace is gone
+		if job.OutputPath != "" {
+			job.Frames = renderState.SavedFrames
+		}
+
+		switch {
+		case renderState.Cancelled:
+			job.Status = state.JobCancelled
+			nodeState.SetPhase(state.PhaseCancelled)
+		case err != nil:
+			logrus.Errorf("Could not run job %s: %s\n", job.ID, err)
+			job.Status = state.JobFailed
+			job.Error = err.Error()
+			nodeState.SetPhase(state.PhaseFailed)
+		default:
+			job.Status = state.JobCompleted
+		}
+
+		jobs.UpdateJob(job, cfg)
+
+		// A failure stays visible through the last job and the transition history, the node itself is idle again
+		nodeState.FinishRender(job)
+		nodeState.SetPhase(state.PhaseIdle)
+		EvictWorkspaces(cfg)
+	}
+}
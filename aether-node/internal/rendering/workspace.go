@@ -0,0 +1,278 @@
+package rendering
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io/fs"
+	"node/internal/archive"
+	"node/internal/config"
+	"node/internal/state"
+	"node/internal/util"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Written into an extracted scene once extraction has finished; A workspace without it is incomplete
+const manifestFile = ".aether-manifest.json"
+
+type manifestEntry struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+type workspaceManifest struct {
+	Checksum    string          `json:"checksum"`
+	SceneFile   string          `json:"scene_file"`
+	ExtractedAt int64           `json:"extracted_at"`
+	LastUsedAt  int64           `json:"last_used_at"`
+	Size        uint64          `json:"size"`
+	Files       []manifestEntry `json:"files"`
+}
+
+// Extracted scenes are shared by all jobs and keyed by the checksum of the scene file
+func sceneWorkspacePath(cfg *config.NodeConfig, scene *state.SceneMetadata) string {
+	return filepath.Join(cfg.Data.WorkspaceDirectory, "scenes", hex.EncodeToString(scene.Checksum))
+}
+
+// Every job renders into its own directory, so the extracted scene stays untouched
+func JobOutputDir(cfg *config.NodeConfig, jobId uuid.UUID) string {
+	return filepath.Join(cfg.Data.WorkspaceDirectory, "jobs", jobId.String())
+}
+
+func readManifest(workspace string) (*workspaceManifest, error) {
+	content, err := os.ReadFile(filepath.Join(workspace, manifestFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest workspaceManifest
+	if err = json.Unmarshal(content, &manifest); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+func writeManifest(workspace string, manifest *workspaceManifest) error {
+	content, err := json.MarshalIndent(manifest, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(workspace, manifestFile), content, 0644)
+}
+
+// Record all extracted files, so a later job can tell whether the workspace is still intact
+func buildManifest(workspace string, scene *state.SceneMetadata) (*workspaceManifest, error) {
+	now := time.Now().UnixNano()
+	manifest := &workspaceManifest{
+		Checksum:    hex.EncodeToString(scene.Checksum),
+		SceneFile:   scene.Filename,
+		ExtractedAt: now,
+		LastUsedAt:  now,
+		Files:       []manifestEntry{},
+	}
+
+	err := filepath.WalkDir(workspace, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(workspace, path)
+		if err != nil {
+			return err
+		}
+		if rel == manifestFile {
+			return nil
+		}
+
+		manifest.Files = append(manifest.Files, manifestEntry{Path: filepath.ToSlash(rel), Size: info.Size()})
+		manifest.Size += uint64(info.Size())
+		return nil
+	})
+
+	return manifest, err
+}
+
+// Whether a workspace was extracted from the given scene and none of its files went missing or changed size
+func (manifest *workspaceManifest) matches(workspace string, scene *state.SceneMetadata) bool {
+	if manifest.Checksum != hex.EncodeToString(scene.Checksum) {
+		return false
+	}
+
+	for _, entry := range manifest.Files {
+		info, err := os.Stat(filepath.Join(workspace, filepath.FromSlash(entry.Path)))
+		if err != nil || info.Size() != entry.Size {
+			logrus.Debugf("Workspace file %s is missing or was modified\n", entry.Path)
+			return false
+		}
+	}
+	return true
+}
+
+// Extract the scene, unless an intact workspace of it already exists. Returns the workspace directory.
+func prepareWorkspace(cfg *config.NodeConfig, scene *state.SceneMetadata, onProgress func(util.ExtractProgress)) (string, error) {
+	path := sceneWorkspacePath(cfg, scene)
+
+	if manifest, err := readManifest(path); err == nil && manifest.matches(path, scene) {
+		logrus.Infof("Reusing workspace of scene %s (%s)\n", scene.ID, humanize.Bytes(manifest.Size))
+
+		manifest.LastUsedAt = time.Now().UnixNano()
+		if err := writeManifest(path, manifest); err != nil {
+			logrus.Errorf("Could not update workspace manifest: %s\n", err)
+		}
+
+		files := len(manifest.Files)
+		onProgress(util.ExtractProgress{FilesDone: files, FilesTotal: files, BytesDone: manifest.Size, BytesTotal: manifest.Size})
+		return path, nil
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		logrus.Debugf("Cleaning up outdated workspace: %s\n", path)
+		if err := os.RemoveAll(path); err != nil {
+			logrus.Debugf("Failed to remove directory: %s\n", err)
+			return "", err
+		}
+	}
+
+	err := os.MkdirAll(path, 0777)
+	if err != nil {
+		logrus.Debugf("Could not create scene directory in workspace (%s): %s\n", path, err)
+		return "", err
+	}
+
+	scenePath := filepath.Join(cfg.Data.ScenesDirectory, scene.Filename)
+
+	logrus.Debugf("Decompressing (%s) into (%s) ...\n", scenePath, path)
+
+	err = archive.Extract(scenePath, path, cfg.ExtractionLimits(), onProgress)
+	if err != nil {
+		logrus.Debugf("Could not decompress scene file (%s): %s\n", path, err)
+		// Do not leave a partial extraction behind for the next job
+		if err := os.RemoveAll(path); err != nil {
+			logrus.Debugf("Failed to remove directory: %s\n", err)
+		}
+		return "", err
+	}
+
+	logrus.Debugf("Decompressing complete.")
+
+	manifest, err := buildManifest(path, scene)
+	if err == nil {
+		err = writeManifest(path, manifest)
+	}
+	if err != nil {
+		// The workspace works for this job, it just can not be reused
+		logrus.Errorf("Could not write workspace manifest: %s\n", err)
+	}
+
+	return path, nil
+}
+
+// Delete the output directory of a job
+func cleanupJobOutput(cfg *config.NodeConfig, jobId uuid.UUID) error {
+	path := JobOutputDir(cfg, jobId)
+	err := os.RemoveAll(path)
+	if err != nil {
+		logrus.Errorf("Could not remove job output directory (%s): %s\n", path, err)
+		return err
+	}
+
+	for _, file := range []string{savedFramesLog(path), sceneScriptPath(path)} {
+		err = os.Remove(file)
+		if err != nil && !os.IsNotExist(err) {
+			logrus.Errorf("Could not remove %s of job %s: %s\n", filepath.Base(file), jobId, err)
+			return err
+		}
+	}
+
+	logrus.Debugf("Removed job output directory: %s", path)
+	return nil
+}
+
+// Remove extracted scenes that have not been used for longer than max_age, then the least recently used ones
+// until the rest fits into max_size. Output directories left behind by interrupted jobs are removed after max_age.
+// Must not run while a job is being rendered.
+func EvictWorkspaces(cfg *config.NodeConfig) {
+	maxAge := cfg.Workspaces.MaxAge
+	maxSize := cfg.Workspaces.MaxSizeBytes
+
+	scenesDir := filepath.Join(cfg.Data.WorkspaceDirectory, "scenes")
+	entries, err := os.ReadDir(scenesDir)
+	if err != nil && !os.IsNotExist(err) {
+		logrus.Errorf("Could not read workspace directory: %s\n", err)
+	}
+
+	type cachedWorkspace struct {
+		path     string
+		manifest *workspaceManifest
+	}
+
+	var workspaces []cachedWorkspace
+	var totalSize uint64
+	for _, entry := range entries {
+		path := filepath.Join(scenesDir, entry.Name())
+
+		manifest, err := readManifest(path)
+		if err != nil {
+			// The extraction of this workspace never finished
+			evictWorkspace(path, "incomplete")
+			continue
+		}
+
+		if maxAge > 0 && time.Since(time.Unix(0, manifest.LastUsedAt)) > maxAge {
+			evictWorkspace(path, "unused for more than "+maxAge.String())
+			continue
+		}
+
+		workspaces = append(workspaces, cachedWorkspace{path: path, manifest: manifest})
+		totalSize += manifest.Size
+	}
+
+	if maxSize > 0 && totalSize > maxSize {
+		sort.Slice(workspaces, func(i, j int) bool {
+			return workspaces[i].manifest.LastUsedAt < workspaces[j].manifest.LastUsedAt
+		})
+
+		for _, workspace := range workspaces {
+			if totalSize <= maxSize {
+				break
+			}
+			evictWorkspace(workspace.path, "workspaces exceed "+humanize.Bytes(maxSize))
+			totalSize -= workspace.manifest.Size
+		}
+	}
+
+	if maxAge == 0 {
+		return
+	}
+
+	jobsDir := filepath.Join(cfg.Data.WorkspaceDirectory, "jobs")
+	entries, err = os.ReadDir(jobsDir)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err == nil && time.Since(info.ModTime()) > maxAge {
+			evictWorkspace(filepath.Join(jobsDir, entry.Name()), "unused for more than "+maxAge.String())
+		}
+	}
+}
+
+func evictWorkspace(path string, reason string) {
+	logrus.Infof("Evicting workspace %s: %s\n", path, reason)
+	if err := os.RemoveAll(path); err != nil {
+		logrus.Errorf("Could not remove workspace (%s): %s\n", path, err)
+	}
+}
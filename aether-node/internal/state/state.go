@@ -1,10 +1,16 @@
 package state
 
 import (
+	"errors"
+	"node/internal/blender"
 	"node/internal/checksum"
 	"node/internal/dto/render"
 	"node/internal/util"
+	"os"
+	"path"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -15,21 +21,275 @@ type SceneMetadata struct {
 	Filename     string            `json:"filename"`
 	OriginalName string            `json:"original_name"`
 	ID           uuid.UUID         `json:"id"`
+	// Relative paths of all *.blend files in the scene
+	BlendFiles []string `json:"blend_files"`
+	// The file to render unless a request names another one; Optional if there is only one *.blend file
+	MainFile string `json:"main_file"`
+}
+
+var ErrNoBlendFile = errors.New("the scene does not contain a *.blend file")
+var ErrAmbiguousBlendFile = errors.New("the scene contains several *.blend files, the main file has to be named by \"blend_file\" or \"main_file\" on upload")
+var ErrUnknownBlendFile = errors.New("the scene does not contain the requested *.blend file")
+
+// Normalize a relative path given by a client, so it can be compared with BlendFiles
+func CleanScenePath(p string) string {
+	return strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(p, "\\", "/")), "/")
+}
+
+// Pick the *.blend file to render: The one named by the request, else the main file, else the only one there is
+func (scene *SceneMetadata) SelectBlendFile(requested string) (string, error) {
+	if requested == "" {
+		requested = scene.MainFile
+	}
+
+	if requested != "" {
+		requested = CleanScenePath(requested)
+		for _, file := range scene.BlendFiles {
+			if file == requested {
+				return file, nil
+			}
+		}
+		return "", ErrUnknownBlendFile
+	}
+
+	switch len(scene.BlendFiles) {
+	case 0:
+		return "", ErrNoBlendFile
+	case 1:
+		return scene.BlendFiles[0], nil
+	default:
+		return "", ErrAmbiguousBlendFile
+	}
+}
+
+type JobStatus string
+
+const (
+	JobQueued    JobStatus = "QUEUED"
+	JobRunning   JobStatus = "RUNNING"
+	JobCompleted JobStatus = "COMPLETED"
+	JobFailed    JobStatus = "FAILED"
+	JobCancelled JobStatus = "CANCELLED"
+	// The node went down while the job was running; It can be resumed
+	JobInterrupted JobStatus = "INTERRUPTED"
+)
+
+type IssueKind string
+
+const (
+	IssueWarning   IssueKind = "WARNING"
+	IssueError     IssueKind = "ERROR"
+	IssueTraceback IssueKind = "TRACEBACK"
+)
+
+// A warning, error or Python traceback Blender printed while rendering
+type OutputIssue struct {
+	Kind    IssueKind `json:"kind"`
+	Message string    `json:"message"`
+	Details string    `json:"details,omitempty"`
+	// Frame Blender was working on; nil before it reported any
+	Frame *int `json:"frame"`
+}
+
+// A frame Blender has written to the output directory of a job
+type SavedFrame struct {
+	Frame int    `json:"frame"`
+	File  string `json:"file"`
+}
+
+type Job struct {
+	ID           uuid.UUID            `json:"id"`
+	Request      render.RenderRequest `json:"request"`
+	Status       JobStatus            `json:"status"`
+	QueuedAt     int64                `json:"queued_at"`
+	StartedAt    int64                `json:"started_at"`
+	FinishedAt   int64                `json:"finished_at"`
+	ExitCode     *int                 `json:"exit_code"`
+	Signal       string               `json:"signal"`
+	Error        string               `json:"error"`
+	OutputTail   []string             `json:"output_tail"`
+	OutputPath   string               `json:"output_path"`
+	LogPath      string               `json:"log_path"`
+	CrashReport  string               `json:"crash_report_path"`
+	Frames       []SavedFrame         `json:"frames"`
+	Resume       bool                 `json:"resume"`
+	Issues       []OutputIssue        `json:"issues"`
+	WarningCount int                  `json:"warning_count"`
+	ErrorCount   int                  `json:"error_count"`
+}
+
+// Whether the job has reached a final state and will not change anymore
+func (job *Job) IsFinished() bool {
+	return job.Status == JobCompleted || job.Status == JobFailed || job.Status == JobCancelled || job.Status == JobInterrupted
 }
 
 type RendererState struct {
-	Scene         SceneMetadata
-	Request       render.RenderRequest
-	CurrentFrame  int
-	FramePercent  float64
-	TimeElapsed   float64
-	TimeRemaining float64
+	JobID          uuid.UUID
+	Scene          SceneMetadata
+	Request        render.RenderRequest
+	CurrentFrame   int
+	FrameCount     int
+	FramesDone     int
+	FramePercent   float64
+	TimeElapsed    float64
+	TimeRemaining  float64
+	Sample         int
+	SampleCount    int
+	Tile           int
+	TileCount      int
+	PeakMemory     float64
+	Process        *os.Process
+	Cancelled      bool
+	KeepPartial    bool
+	Resume         bool
+	StartedAt      time.Time
+	Paused         bool
+	PausedAt       time.Time
+	PausedTotal    time.Duration
+	FramePaused    time.Duration
+	ExitCode       *int
+	Signal         string
+	OutputTail     []string
+	Issues         []OutputIssue
+	WarningCount   int
+	ErrorCount     int
+	OutputPath     string
+	LogPath        string
+	CrashReport    string
+	BlenderPath    string
+	OutputDir      string
+	SavedFrames    []SavedFrame
+	LastOutputAt   time.Time
+	WatchdogReason string
+	Preparation    util.ExtractProgress
+}
+
+func (renderState *RendererState) clone() *RendererState {
+	if renderState == nil {
+		return nil
+	}
+
+	c := *renderState
+	c.OutputTail = append([]string(nil), renderState.OutputTail...)
+	c.SavedFrames = append([]SavedFrame(nil), renderState.SavedFrames...)
+	c.Issues = append([]OutputIssue(nil), renderState.Issues...)
+	return &c
+}
+
+// Time spent on the job so far, not counting the time it was paused
+func (renderState *RendererState) ActiveTime() time.Duration {
+	now := time.Now()
+	active := now.Sub(renderState.StartedAt) - renderState.PausedTotal
+	if renderState.Paused {
+		active -= now.Sub(renderState.PausedAt)
+	}
+	return active
+}
+
+type Phase string
+
+const (
+	PhaseIdle       Phase = "IDLE"
+	PhasePreparing  Phase = "PREPARING"
+	PhaseRendering  Phase = "RENDERING"
+	PhaseCollecting Phase = "COLLECTING"
+	PhaseFailed     Phase = "FAILED"
+	PhaseCancelled  Phase = "CANCELLED"
+)
+
+type PhaseTransition struct {
+	Phase Phase `json:"phase"`
+	At    int64 `json:"at"`
 }
 
 type State struct {
+	UploadLock sync.Mutex
+
+	// Guards all fields below; They are only accessed through the methods of State
+	mutex         sync.RWMutex
+	rendererState *RendererState
+	lastJob       *Job
+	phase         Phase
+	transitions   []PhaseTransition
+}
+
+// Consistent copy of the node state at a single point in time
+type Snapshot struct {
 	RendererState *RendererState
-	UploadLock    sync.Mutex
-	RenderLock    sync.Mutex
+	LastJob       *Job
+	Phase         Phase
+	PhaseSince    int64
+	Transitions   []PhaseTransition
+}
+
+func (s *State) Snapshot() Snapshot {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	snapshot := Snapshot{
+		RendererState: s.rendererState.clone(),
+		Phase:         s.phase,
+		Transitions:   append([]PhaseTransition{}, s.transitions...),
+	}
+
+	if s.lastJob != nil {
+		lastJob := *s.lastJob
+		snapshot.LastJob = &lastJob
+	}
+
+	if len(s.transitions) > 0 {
+		snapshot.PhaseSince = s.transitions[len(s.transitions)-1].At
+	}
+
+	return snapshot
+}
+
+// Return a copy of the current renderer state, or nil if no job is running
+func (s *State) Renderer() *RendererState {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.rendererState.clone()
+}
+
+// Install the renderer state of a job that is about to start
+func (s *State) StartRender(renderState RendererState) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.rendererState = &renderState
+}
+
+// Modify the renderer state while holding the state lock. Returns false if no job is running.
+func (s *State) UpdateRenderer(update func(renderState *RendererState)) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.rendererState == nil {
+		return false
+	}
+
+	update(s.rendererState)
+	return true
+}
+
+// Clear the renderer state and remember the finished job
+func (s *State) FinishRender(job Job) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.rendererState = nil
+	s.lastJob = &job
+}
+
+// Move the node into another phase. Starting a new job (PREPARING) begins a fresh transition history.
+func (s *State) SetPhase(phase Phase) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if phase == PhasePreparing {
+		s.transitions = nil
+	}
+
+	s.phase = phase
+	s.transitions = append(s.transitions, PhaseTransition{Phase: phase, At: time.Now().UnixNano()})
 }
 
 type Platform int
@@ -46,12 +306,17 @@ type AetherNode struct {
 	Color    util.RGBColor
 	State    State
 	Platform Platform
+	Blenders *blender.Registry
 }
 
 func (node *AetherNode) NodeInfoMap() map[string]interface{} {
 	return map[string]interface{}{
-		"id":   node.ID.String(),
-		"name": node.Name,
+		"id":                 node.ID.String(),
+		"name":               node.Name,
+		"blenders":           node.Blenders.Installations(),
+		"blender_version":    node.Blenders.Default().Version,
+		"blender_build_hash": node.Blenders.Default().BuildHash,
+		"degraded":           node.Blenders.Degraded(),
 		"color": map[string]interface{}{
 			"r": node.Color.R,
 			"g": node.Color.G,
@@ -0,0 +1,102 @@
+package state
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// Readers take snapshots while the worker drives jobs through their phases; Run with -race
+func TestStateConcurrentAccess(t *testing.T) {
+	var s State
+	var wg sync.WaitGroup
+
+	// Each reader only uses one accessor, so the locking of one can not hide missing locking in the other
+	readers := []func(){
+		func() {
+			snapshot := s.Snapshot()
+			if snapshot.RendererState != nil {
+				snapshot.RendererState.Issues = append(snapshot.RendererState.Issues, OutputIssue{Kind: IssueWarning})
+				snapshot.RendererState.SavedFrames = append(snapshot.RendererState.SavedFrames, SavedFrame{})
+			}
+			if snapshot.LastJob != nil {
+				_ = snapshot.LastJob.Status
+			}
+		},
+		func() {
+			if renderer := s.Renderer(); renderer != nil {
+				_ = renderer.CurrentFrame
+				renderer.Issues = append(renderer.Issues, OutputIssue{Kind: IssueWarning})
+			}
+		},
+	}
+
+	// Yielding after every step interleaves the goroutines even on a single CPU
+	for i := 0; i < 4; i++ {
+		for _, read := range readers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for n := 0; n < 2000; n++ {
+					read()
+					runtime.Gosched()
+				}
+			}()
+		}
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for job := 0; job < 100; job++ {
+			s.SetPhase(PhasePreparing)
+			s.StartRender(RendererState{JobID: uuid.New(), FrameCount: 10})
+			s.SetPhase(PhaseRendering)
+			runtime.Gosched()
+
+			for frame := 1; frame <= 10; frame++ {
+				s.UpdateRenderer(func(renderState *RendererState) {
+					renderState.CurrentFrame = frame
+					renderState.Issues = append(renderState.Issues, OutputIssue{Kind: IssueError, Frame: &frame})
+					renderState.SavedFrames = append(renderState.SavedFrames, SavedFrame{Frame: frame})
+				})
+				runtime.Gosched()
+			}
+
+			s.SetPhase(PhaseCollecting)
+			s.FinishRender(Job{ID: uuid.New(), Status: JobCompleted})
+			s.SetPhase(PhaseIdle)
+			runtime.Gosched()
+		}
+	}()
+
+	wg.Wait()
+
+	snapshot := s.Snapshot()
+	if snapshot.RendererState != nil {
+		t.Errorf("expected no running job, got %s", snapshot.RendererState.JobID)
+	}
+	if snapshot.Phase != PhaseIdle {
+		t.Errorf("expected phase %s, got %s", PhaseIdle, snapshot.Phase)
+	}
+	if snapshot.LastJob == nil || snapshot.LastJob.Status != JobCompleted {
+		t.Errorf("expected the last job to be completed, got %+v", snapshot.LastJob)
+	}
+}
+
+// Copies handed out by the state must not share memory with it
+func TestRendererReturnsCopy(t *testing.T) {
+	var s State
+	s.StartRender(RendererState{SavedFrames: []SavedFrame{{Frame: 1}}})
+
+	renderer := s.Renderer()
+	renderer.SavedFrames[0].Frame = 42
+	renderer.CurrentFrame = 7
+
+	current := s.Renderer()
+	if current.SavedFrames[0].Frame != 1 || current.CurrentFrame != 0 {
+		t.Errorf("modifying a copy changed the state: %+v", current)
+	}
+}
@@ -2,12 +2,10 @@ package util
 
 import (
 	"archive/zip"
-	"fmt"
 	"io"
 	"mime/multipart"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/k0kubun/go-ansi"
 	"github.com/schollz/progressbar/v3"
@@ -101,60 +99,9 @@ func CompressZip(src string, dstZip string) error {
 	return err
 }
 
-func DecompressZip(src string, dst string) error {
-	reader, err := zip.OpenReader(src)
-	if err != nil {
-		return err
-	}
-	defer reader.Close()
-
-	bar := SyntheticProgressBar(len(reader.File), "UNZIP")
-	bar.RenderBlank()
-
-	for _, zipFile := range reader.File {
-		path := filepath.Join(dst, zipFile.Name)
-
-		if strings.HasPrefix(strings.ToLower(zipFile.Name), "__macosx/") ||
-			strings.HasSuffix(strings.ToLower(zipFile.Name), ".ds_store") {
-			bar.Add(1)
-			continue
-		}
-
-		if zipFile.FileInfo().IsDir() {
-			os.MkdirAll(path, 0777)
-			bar.Add(1)
-			continue
-		}
-
-		// Create all directories leading up to the file
-		err = os.MkdirAll(filepath.Dir(path), 0777)
-		if err != nil {
-			return err
-		}
-
-		// Open Destination file
-		dstFile, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0777)
-		if err != nil {
-			return err
-		}
-
-		// Open Source file
-		srcReader, err := zipFile.Open()
-		if err != nil {
-			return err
-		}
-
-		_, err = io.Copy(dstFile, srcReader)
-		if err != nil {
-			return err
-		}
-
-		bar.Add(1)
-		dstFile.Close()
-		srcReader.Close()
-	}
-
-	fmt.Println()
-
-	return nil
+type ExtractProgress struct {
+	FilesDone  int    `json:"files_done"`
+	FilesTotal int    `json:"files_total"`
+	BytesDone  uint64 `json:"bytes_done"`
+	BytesTotal uint64 `json:"bytes_total"`
 }